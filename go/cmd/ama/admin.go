@@ -0,0 +1,477 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/auth"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/export"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
+)
+
+const adminUsage = `usage: ama admin [-org <slug>] <command>
+
+Room and key commands act within the organization given by -org, which
+defaults to the default organization.
+
+Organizations:
+  orgs list                      list organizations
+  orgs create <slug> <name>      create an organization
+
+API keys:
+  keys list                      list API keys with their scopes and last use
+  keys create [-scopes <list>] <name>
+                                 create an API key, which is only shown once,
+                                 with comma-separated scopes out of
+                                 rooms:write, questions:moderate and read-only
+                                 (the default)
+  keys revoke <key_id>           stop accepting an API key
+
+Rooms:
+  rooms list [-deleted]          list rooms with their question count,
+                                 including deleted ones with -deleted
+  rooms create <name>            create a room
+  rooms close <room_id>          stop accepting new questions
+  rooms reopen <room_id>         accept new questions again
+  rooms delete <room_id>         delete a room and its questions
+  rooms purge <room_id>          delete every question of a room
+  rooms export <room_id> [fmt]   print a room and its questions as json, csv or md
+
+Questions:
+  questions list <room_id>       list the questions of a room
+  questions answer <question_id> mark a question as answered`
+
+type adminCommand func(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error
+
+var adminCommands = map[string]adminCommand{
+	"orgs list":        adminListOrganizations,
+	"orgs create":      adminCreateOrganization,
+	"keys list":        adminListAPIKeys,
+	"keys create":      adminCreateAPIKey,
+	"keys revoke":      adminRevokeAPIKey,
+	"rooms list":       adminListRooms,
+	"rooms create":     adminCreateRoom,
+	"rooms close":      adminCloseRoom,
+	"rooms reopen":     adminReopenRoom,
+	"rooms delete":     adminDeleteRoom,
+	"rooms purge":      adminPurgeRoom,
+	"rooms export":     adminExportRoom,
+	"questions list":   adminListQuestions,
+	"questions answer": adminAnswerQuestion,
+}
+
+func runAdmin(ctx context.Context, args []string) error {
+	organizationSlug := "default"
+	if len(args) > 1 && args[0] == "-org" {
+		organizationSlug, args = args[1], args[2:]
+	}
+
+	if len(args) < 2 {
+		return errors.New(adminUsage)
+	}
+
+	command, ok := adminCommands[args[0]+" "+args[1]]
+	if !ok {
+		return errors.New(adminUsage)
+	}
+
+	conn, err := pgx.Connect(ctx, connString())
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	query := postgres.New(conn)
+	organization, err := query.GetOrganizationBySlug(ctx, organizationSlug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("organization %s not found", organizationSlug)
+	}
+	if err != nil {
+		return err
+	}
+
+	return command(ctx, query, organization, args[2:])
+}
+
+func adminListOrganizations(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	organizations, err := query.GetOrganizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tSLUG\tNAME\tCREATED AT")
+	for _, organization := range organizations {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", organization.ID, organization.Slug, organization.Name, formatTime(organization.CreatedAt))
+	}
+
+	return writer.Flush()
+}
+
+func adminCreateOrganization(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: ama admin orgs create <slug> <name>")
+	}
+
+	slug := strings.ToLower(args[0])
+	if err := service.ValidateSlug(slug); err != nil {
+		return err
+	}
+
+	created, err := query.CreateOrganization(ctx, postgres.CreateOrganizationParams{
+		Name: strings.TrimSpace(strings.Join(args[1:], " ")),
+		Slug: slug,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Organization: %s\nSlug:         %s\n", created.ID, created.Slug)
+	return nil
+}
+
+func adminListAPIKeys(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	keys, err := query.GetOrganizationAPIKeys(ctx, organization.ID)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tSCOPES\tSTATUS\tLAST USED AT\tCREATED AT")
+	for _, key := range keys {
+		status := "active"
+		if key.RevokedAt.Valid {
+			status = "revoked"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, strings.Join(key.Scopes, ","), status, formatTime(key.LastUsedAt), formatTime(key.CreatedAt))
+	}
+
+	return writer.Flush()
+}
+
+func adminCreateAPIKey(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	var scopes []string
+	if len(args) > 1 && args[0] == "-scopes" {
+		scopes, args = strings.Split(args[1], ","), args[2:]
+	}
+
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if name == "" {
+		return errors.New("usage: ama admin keys create [-scopes <list>] <name>")
+	}
+
+	scopes, err := service.ValidateScopes(scopes)
+	if err != nil {
+		return err
+	}
+
+	secret, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	key, err := query.CreateAPIKey(ctx, postgres.CreateAPIKeyParams{
+		OrganizationID: organization.ID,
+		Name:           name,
+		KeyHash:        auth.HashToken(secret),
+		Scopes:         scopes,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("API key:      %s\nOrganization: %s\nScopes:       %s\nKey:          %s\n", key.ID, organization.Slug, strings.Join(key.Scopes, ","), secret)
+	return nil
+}
+
+func adminRevokeAPIKey(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	keyID, err := parseIDArg(args, "key_id")
+	if err != nil {
+		return err
+	}
+
+	key, err := query.RevokeAPIKey(ctx, postgres.RevokeAPIKeyParams{ID: keyID, OrganizationID: organization.ID})
+	if err != nil {
+		return notFound(err, "API key", keyID)
+	}
+
+	fmt.Printf("API key %s (%s) revoked at %s\n", key.ID, key.Name, formatTime(key.RevokedAt))
+	return nil
+}
+
+func adminListRooms(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	includeDeleted := len(args) > 0 && args[0] == "-deleted"
+	rooms, err := query.GetRoomsWithQuestionCount(ctx, postgres.GetRoomsWithQuestionCountParams{
+		OrganizationID: organization.ID,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tSLUG\tNAME\tSTATUS\tVISIBILITY\tQUESTIONS\tCREATED AT")
+	for _, room := range rooms {
+		status := roomStatus(room.ClosedAt)
+		if room.DeletedAt.Valid {
+			status = "deleted"
+		}
+
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", room.ID, room.Slug, room.Name, status, room.Visibility, room.QuestionCount, formatTime(room.CreatedAt))
+	}
+
+	return writer.Flush()
+}
+
+func adminCreateRoom(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	name := strings.TrimSpace(strings.Join(args, " "))
+	if name == "" {
+		return errors.New("usage: ama admin rooms create <name>")
+	}
+
+	ownerToken, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	slug, err := service.NewSlug(name)
+	if err != nil {
+		return err
+	}
+
+	// Rooms outside the default organization are only entered with their
+	// join code, like the API creates them.
+	var joinCode pgtype.Text
+	if organization.ID != db.DefaultOrganizationID {
+		code, err := auth.NewJoinCode()
+		if err != nil {
+			return err
+		}
+		joinCode = pgtype.Text{String: code, Valid: true}
+	}
+
+	room, err := query.CreateRoom(ctx, postgres.CreateRoomParams{
+		Name:            name,
+		OwnerTokenHash:  auth.HashToken(ownerToken),
+		Visibility:      service.RoomVisibilityPublic,
+		JoinCode:        joinCode,
+		Slug:            slug,
+		ReactionKinds:   []string{service.DefaultReactionKind},
+		PrimaryReaction: service.DefaultReactionKind,
+		Ranking:         service.RankingScore,
+		OrganizationID:  organization.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Room:        %s\nSlug:        %s\nOwner token: %s\n", room.ID, room.Slug, ownerToken)
+	if room.JoinCode.Valid {
+		fmt.Printf("Join code:   %s\n", room.JoinCode.String)
+	}
+	return nil
+}
+
+func adminCloseRoom(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	roomID, err := parseIDArg(args, "room_id")
+	if err != nil {
+		return err
+	}
+
+	room, err := query.CloseRoom(ctx, postgres.CloseRoomParams{ID: roomID, OrganizationID: organization.ID})
+	if err != nil {
+		return notFound(err, "room", roomID)
+	}
+
+	fmt.Printf("Room %s closed at %s\n", room.ID, formatTime(room.ClosedAt))
+	return nil
+}
+
+func adminReopenRoom(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	roomID, err := parseIDArg(args, "room_id")
+	if err != nil {
+		return err
+	}
+
+	room, err := query.ReopenRoom(ctx, postgres.ReopenRoomParams{ID: roomID, OrganizationID: organization.ID})
+	if err != nil {
+		return notFound(err, "room", roomID)
+	}
+
+	fmt.Printf("Room %s reopened\n", room.ID)
+	return nil
+}
+
+func adminDeleteRoom(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	roomID, err := parseIDArg(args, "room_id")
+	if err != nil {
+		return err
+	}
+
+	deleted, err := query.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: roomID, OrganizationID: organization.ID})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return notFound(pgx.ErrNoRows, "room", roomID)
+	}
+
+	fmt.Printf("Room %s deleted\n", roomID)
+	return nil
+}
+
+func adminPurgeRoom(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	roomID, err := parseIDArg(args, "room_id")
+	if err != nil {
+		return err
+	}
+
+	if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: roomID, OrganizationID: organization.ID}); err != nil {
+		return notFound(err, "room", roomID)
+	}
+
+	deleted, err := query.DeleteRoomQuestions(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %d questions from room %s\n", deleted, roomID)
+	return nil
+}
+
+func adminExportRoom(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	format := export.JSON
+	if len(args) == 2 {
+		var err error
+		if format, err = export.ParseFormat(args[1]); err != nil {
+			return err
+		}
+
+		args = args[:1]
+	}
+
+	roomID, err := parseIDArg(args, "room_id")
+	if err != nil {
+		return err
+	}
+
+	room, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: roomID, OrganizationID: organization.ID})
+	if err != nil {
+		return notFound(err, "room", roomID)
+	}
+
+	questions, err := query.GetRoomQuestionsByPopularity(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	return export.Write(os.Stdout, format, room, questions)
+}
+
+func adminListQuestions(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	roomID, err := parseIDArg(args, "room_id")
+	if err != nil {
+		return err
+	}
+
+	if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: roomID, OrganizationID: organization.ID}); err != nil {
+		return notFound(err, "room", roomID)
+	}
+
+	questions, err := query.GetRoomQuestions(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	var answered, reactions int64
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tREACTIONS\tANSWERED\tCREATED AT\tTEXT")
+	for _, question := range questions {
+		if question.Answered {
+			answered++
+		}
+		reactions += question.ReactionCount
+
+		fmt.Fprintf(writer, "%s\t%d\t%t\t%s\t%s\n", question.ID, question.ReactionCount, question.Answered, formatTime(question.CreatedAt), question.Text)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d questions, %d answered, %d unanswered, %d reactions\n", len(questions), answered, int64(len(questions))-answered, reactions)
+	return nil
+}
+
+func adminAnswerQuestion(ctx context.Context, query *postgres.Queries, organization postgres.Organization, args []string) error {
+	questionID, err := parseIDArg(args, "question_id")
+	if err != nil {
+		return err
+	}
+
+	question, err := query.GetQuestion(ctx, questionID)
+	if err != nil {
+		return notFound(err, "question", questionID)
+	}
+
+	// Questions of other organizations' rooms are out of reach too.
+	if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: question.RoomID, OrganizationID: organization.ID}); err != nil {
+		return notFound(err, "question", questionID)
+	}
+
+	if err := query.MarkQuestionAsAnswered(ctx, questionID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Question %s marked as answered\n", questionID)
+	return nil
+}
+
+func parseIDArg(args []string, name string) (uuid.UUID, error) {
+	if len(args) != 1 {
+		return uuid.UUID{}, fmt.Errorf("expected a single <%s> argument", name)
+	}
+
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid %s %q", name, args[0])
+	}
+
+	return id, nil
+}
+
+func notFound(err error, entity string, id uuid.UUID) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s %s not found", entity, id)
+	}
+
+	return err
+}
+
+func roomStatus(closedAt pgtype.Timestamptz) string {
+	if closedAt.Valid {
+		return "closed"
+	}
+
+	return "open"
+}
+
+func formatTime(timestamp pgtype.Timestamptz) string {
+	if !timestamp.Valid {
+		return ""
+	}
+
+	return timestamp.Time.Local().Format(time.DateTime)
+}
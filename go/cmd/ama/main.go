@@ -3,13 +3,17 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/pedrogiorgetti/ama/go/internal/api"
 	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres/migrations"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -20,27 +24,71 @@ func main() {
 		panic(err)
 	}
 
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
 	ctx := context.Background()
+	args := os.Args[1:]
+
+	var err error
+	switch {
+	case len(args) > 0 && args[0] == "migrate":
+		err = runMigrate(ctx, args[1:])
+	case len(args) > 0 && args[0] == "admin":
+		err = runAdmin(ctx, args[1:])
+	default:
+		err = runServer(ctx, args)
+	}
 
-	pool, err := pgxpool.New(ctx, fmt.Sprintf(
-		"user=%s password=%s host=%s port=%s dbname=%s",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	))
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runServer(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ama", flag.ExitOnError)
+	migrateOnStart := flags.Bool("migrate-on-start", false, "apply pending migrations before serving")
+	_ = flags.Parse(args)
+
+	pool, err := pgxpool.New(ctx, connString())
+	if err != nil {
+		return err
 	}
 
 	defer pool.Close()
 
 	if err := pool.Ping(ctx); err != nil {
-		panic(err)
+		return err
+	}
+
+	if *migrateOnStart {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+
+		migrator, err := migrations.NewMigrator(ctx, conn.Conn())
+		if err == nil {
+			err = migrator.Up(ctx)
+		}
+
+		conn.Release()
+
+		if err != nil {
+			return fmt.Errorf("migrate on start: %w", err)
+		}
+	}
+
+	config := api.DefaultConfig()
+	if window := os.Getenv("QUESTION_EDIT_WINDOW"); window != "" {
+		config.QuestionEditWindow, err = time.ParseDuration(window)
+		if err != nil {
+			return fmt.Errorf("QUESTION_EDIT_WINDOW: %w", err)
+		}
 	}
+	config.WebURL = os.Getenv("WEB_URL")
 
-	handler := api.NewHandler(postgres.New(pool))
+	handler := api.NewHandler(postgres.NewStore(pool), config)
 
 	go func() {
 		if err := http.ListenAndServe(":8080", handler); err != nil {
@@ -53,4 +101,17 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
-}
\ No newline at end of file
+
+	return nil
+}
+
+func connString() string {
+	return fmt.Sprintf(
+		"user=%s password=%s host=%s port=%s dbname=%s",
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_PORT"),
+		os.Getenv("DB_NAME"),
+	)
+}
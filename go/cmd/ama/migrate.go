@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres/migrations"
+)
+
+const migrateUsage = "usage: ama migrate up|down|status|to <version>"
+
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	conn, err := pgx.Connect(ctx, connString())
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	migrator, err := migrations.NewMigrator(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+
+		return migrator.To(ctx, int32(version))
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(writer, "Current version: %d of %d\n\n", status.Current, len(status.Migrations))
+		fmt.Fprintln(writer, "VERSION\tAPPLIED\tNAME")
+		for _, migration := range status.Migrations {
+			applied := "no"
+			if migration.Applied {
+				applied = "yes"
+			}
+
+			fmt.Fprintf(writer, "%d\t%s\t%s\n", migration.Version, applied, migration.Name)
+		}
+
+		return writer.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
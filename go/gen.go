@@ -1,4 +1,4 @@
 package gen
 
-//go:generate go run ./cmd/tools/ternDotEnv/main.go
+//go:generate go run ./cmd/ama migrate up
 //go:generate sqlc generate -f ./internal/db/postgres/sqlc.yaml
@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"github.com/jackc/pgx/v5"
-	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -19,53 +23,125 @@ import (
 )
 
 type apiHandler struct {
-	query       *postgres.Queries
-	router      *chi.Mux
-	upgrader    websocket.Upgrader
-	subscribers map[string]map[*websocket.Conn]context.CancelFunc
-	mutex       *sync.Mutex
+	rooms     *service.RoomService
+	questions *service.QuestionService
+	polls     *service.PollService
+	// organizations resolves the organization each request acts within.
+	organizations *service.OrganizationService
+	router        *chi.Mux
+	upgrader      websocket.Upgrader
+	subscribers   map[string]map[*websocket.Conn]context.CancelFunc
+	mutex         *sync.Mutex
+	webURL        string
 }
 
 func (handler apiHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	handler.router.ServeHTTP(writer, request)
 }
 
-func NewHandler(query *postgres.Queries) http.Handler {
+// Config tunes the behaviour of the API.
+type Config struct {
+	// QuestionEditWindow is how long the author of a question can still edit it.
+	QuestionEditWindow time.Duration
+	// WebURL is where the web client is served, e.g. http://localhost:5173.
+	// Short links redirect there, or within this server when it's empty.
+	WebURL string
+}
+
+func DefaultConfig() Config {
+	return Config{
+		QuestionEditWindow: service.DefaultEditWindow,
+	}
+}
+
+func NewHandler(store db.Store, config Config) http.Handler {
 	api := apiHandler{
-		query:       query,
-		upgrader:    websocket.Upgrader{CheckOrigin: func(request *http.Request) bool { return true }},
-		subscribers: make(map[string]map[*websocket.Conn]context.CancelFunc),
-		mutex:       &sync.Mutex{},
+		rooms:         service.NewRoomService(store),
+		questions:     service.NewQuestionService(store, config.QuestionEditWindow),
+		polls:         service.NewPollService(store),
+		organizations: service.NewOrganizationService(store),
+		upgrader:      websocket.Upgrader{CheckOrigin: func(request *http.Request) bool { return true }},
+		subscribers:   make(map[string]map[*websocket.Conn]context.CancelFunc),
+		mutex:         &sync.Mutex{},
+		webURL:        strings.TrimSuffix(config.WebURL, "/"),
 	}
 
 	router := chi.NewRouter()
-	router.Use(middleware.RequestID, middleware.Recoverer, middleware.Logger)
+	router.Use(middleware.RequestID, requestLogger(slog.Default()), middleware.Recoverer)
 
 	router.Use(cors.Handler((cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", ownerTokenHeader, participantIDHeader, joinCodeHeader, organizationHeader},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	})))
+	router.Use(api.resolveOrganization)
+
+	// API keys also need the scope of what they're doing.
+	writeRooms := api.requireScope(service.ScopeRoomsWrite)
+	moderateQuestions := api.requireScope(service.ScopeQuestionsModerate)
 
 	router.Get("/subscribe/{room_id}", api.handleSubscribe)
+	router.Get("/r/{room_id}", api.handleRedirectToRoom)
 
 	router.Route("/api", func(router chi.Router) {
 		router.Route("/rooms", func(router chi.Router) {
-			router.Post("/", api.handleCreateRoom)
+			router.With(writeRooms).Post("/", api.handleCreateRoom)
 			router.Get("/", api.handleGetRooms)
 
-			router.Route("/{room_id}/questions", func(router chi.Router) {
-				router.Post("/", api.handleCreateRoomQuestion)
-				router.Get("/", api.handleGetRoomQuestions)
+			router.Route("/{room_id}", func(router chi.Router) {
+				router.Use(api.requireRoomAccess)
+
+				router.Get("/", api.handleGetRoom)
+				router.With(writeRooms).Delete("/", api.handleDeleteRoom)
+				router.With(writeRooms).Put("/reactions", api.handleUpdateRoomReactions)
+				router.With(writeRooms).Put("/ranking", api.handleUpdateRoomRanking)
+				router.With(writeRooms).Put("/anonymity", api.handleUpdateRoomAnonymity)
+				router.With(moderateQuestions).Put("/spotlight", api.handleSpotlightQuestion)
+				router.With(moderateQuestions).Delete("/spotlight", api.handleClearSpotlight)
+				router.With(moderateQuestions).Put("/pins", api.handleReorderPinnedQuestions)
+				router.Get("/audit", api.handleGetAuditLog)
+				router.Get("/export", api.handleExportRoom)
+				router.With(writeRooms).Post("/import", api.handleImportRoom)
+
+				router.Route("/polls", func(router chi.Router) {
+					router.With(writeRooms).Post("/", api.handleCreatePoll)
+					router.Get("/", api.handleGetPolls)
+
+					router.Route("/{poll_id}", func(router chi.Router) {
+						router.Get("/", api.handleGetPoll)
+						router.With(writeRooms).Patch("/close", api.handleClosePoll)
+						router.With(writeRooms).Patch("/open", api.handleReopenPoll)
+						router.Patch("/vote", api.handleVoteInPoll)
+					})
+				})
 
-				router.Route("/{question_id}", func(router chi.Router) {
-					router.Get("/", api.handleGetRoomQuestion)
-					router.Patch("/react", api.handleReactToQuestion)
-					router.Delete("/react", api.handleRemoveReaction)
-					router.Patch("/answers", api.handleMarkQuestionAsAnswered)
+				router.Route("/questions", func(router chi.Router) {
+					router.Post("/", api.handleCreateRoomQuestion)
+					router.Get("/", api.handleGetRoomQuestions)
+					router.Get("/search", api.handleSearchRoomQuestions)
+
+					router.Route("/{question_id}", func(router chi.Router) {
+						router.Get("/", api.handleGetRoomQuestion)
+						router.Patch("/", api.handleEditQuestion)
+						router.With(moderateQuestions).Delete("/", api.handleDeleteQuestion)
+						router.Get("/edits", api.handleGetQuestionEdits)
+						router.Patch("/react", api.handleReactToQuestion)
+						router.Delete("/react", api.handleRemoveReaction)
+						router.Patch("/downvote", api.handleDownvoteQuestion)
+						router.Delete("/downvote", api.handleRemoveDownvote)
+						router.With(moderateQuestions).Patch("/answers", api.handleMarkQuestionAsAnswered)
+						router.With(moderateQuestions).Patch("/pin", api.handlePinQuestion)
+						router.With(moderateQuestions).Delete("/pin", api.handleUnpinQuestion)
+
+						router.Route("/comments", func(router chi.Router) {
+							router.Get("/", api.handleGetComments)
+							router.Post("/", api.handleCreateComment)
+							router.With(moderateQuestions).Delete("/{comment_id}", api.handleDeleteComment)
+						})
+					})
 				})
 			})
 		})
@@ -80,13 +156,37 @@ const (
 	QuestionCreatedCategory          = "question_created"
 	QuestionReactionIncreaseCategory = "question_reaction_increase"
 	QuestionReactionDecreaseCategory = "question_reaction_decrease"
+	QuestionDownvoteIncreaseCategory = "question_downvote_increase"
+	QuestionDownvoteDecreaseCategory = "question_downvote_decrease"
 	QuestionAnsweredCategory         = "question_answered"
+	QuestionsImportedCategory        = "questions_imported"
+	QuestionDeletedCategory          = "question_deleted"
+	QuestionEditedCategory           = "question_edited"
+	CommentCreatedCategory           = "comment_created"
+	CommentDeletedCategory           = "comment_deleted"
+	QuestionSpotlightedCategory      = "question_spotlighted"
+	SpotlightClearedCategory         = "spotlight_cleared"
+	QuestionPinnedCategory           = "question_pinned"
+	QuestionUnpinnedCategory         = "question_unpinned"
+	PinsReorderedCategory            = "pins_reordered"
+	PollOpenedCategory               = "poll_opened"
+	PollClosedCategory               = "poll_closed"
+	PollResultsCategory              = "poll_results"
 )
 
 type NotificationValue struct {
 	ID    string `json:"id"`
 	Text  string `json:"text"`
 	Count int64  `json:"count"`
+	// Kind and Reactions detail reaction changes.
+	Kind      string            `json:"kind,omitempty"`
+	Reactions service.Reactions `json:"reactions,omitempty"`
+	// QuestionID is the question a comment belongs to.
+	QuestionID string `json:"question_id,omitempty"`
+	// Poll carries the results of the poll for poll notifications.
+	Poll *pollResponse `json:"poll,omitempty"`
+	// AuthorName is the display name a new question was signed with.
+	AuthorName string `json:"author_name,omitempty"`
 }
 
 type Notification struct {
@@ -112,24 +212,39 @@ func (handler apiHandler) handleNotify(notification Notification) {
 	}
 }
 
-func (handler apiHandler) checkIfQuestionExists(questionID uuid.UUID, err error, writer http.ResponseWriter, request *http.Request) bool {
-	if err != nil {
-		http.Error(writer, "Invalid question ID", http.StatusBadRequest)
-		return false
-	}
+// subscriberCount tells how many clients are subscribed to the room right now.
+func (handler apiHandler) subscriberCount(roomID string) int {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
 
-	_, err = handler.query.GetQuestion(request.Context(), questionID)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			http.Error(writer, "Question not found", http.StatusNotFound)
-			return false
-		}
+	return len(handler.subscribers[roomID])
+}
 
-		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
-		return false
+// closeRoomSubscribers disconnects everyone subscribed to the room, telling
+// them why with a close frame.
+func (handler apiHandler) closeRoomSubscribers(roomID string, reason string) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	for connection, cancel := range handler.subscribers[roomID] {
+		_ = connection.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+		cancel()
 	}
+}
 
-	return true
+// requireRoomAccess keeps everyone who can't enter the room out of its routes
+// and hands the resolved room to readRoomID.
+func (handler apiHandler) requireRoomAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		room, _, _, ok := handler.readRoom(writer, request)
+		if !ok {
+			return
+		}
+
+		ctx := context.WithValue(request.Context(), roomContextKey{}, room)
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
 }
 
 func (handler apiHandler) handleSubscribe(writer http.ResponseWriter, request *http.Request) {
@@ -141,7 +256,7 @@ func (handler apiHandler) handleSubscribe(writer http.ResponseWriter, request *h
 
 	connection, err := handler.upgrader.Upgrade(writer, request, nil)
 	if err != nil {
-		slog.Warn("Failed to upgrade connection", "error", err)
+		loggerFromContext(request.Context()).Warn("Failed to upgrade connection", "error", err)
 		http.Error(writer, "Failed to upgrade connection", http.StatusBadRequest)
 		return
 	}
@@ -154,7 +269,7 @@ func (handler apiHandler) handleSubscribe(writer http.ResponseWriter, request *h
 	if _, ok := handler.subscribers[rawRoomID]; !ok {
 		handler.subscribers[rawRoomID] = make(map[*websocket.Conn]context.CancelFunc)
 	}
-	slog.Info("New subscriber", "room_id", rawRoomID, "client_id", request.RemoteAddr)
+	loggerFromContext(request.Context()).Info("New subscriber", "room_id", rawRoomID, "client_id", request.RemoteAddr)
 	handler.subscribers[rawRoomID][connection] = cancel
 	handler.mutex.Unlock()
 
@@ -169,7 +284,13 @@ func (handler apiHandler) handleSubscribe(writer http.ResponseWriter, request *h
 
 func (handler apiHandler) handleCreateRoom(writer http.ResponseWriter, request *http.Request) {
 	type _body struct {
-		Name string `json:"name"`
+		Name            string   `json:"name"`
+		Visibility      string   `json:"visibility"`
+		Slug            string   `json:"slug"`
+		ReactionKinds   []string `json:"reaction_kinds"`
+		PrimaryReaction string   `json:"primary_reaction"`
+		Ranking         string   `json:"ranking"`
+		Anonymous       bool     `json:"anonymous"`
 	}
 	var body _body
 
@@ -178,256 +299,681 @@ func (handler apiHandler) handleCreateRoom(writer http.ResponseWriter, request *
 		return
 	}
 
-	room, err := handler.query.CreateRoom(request.Context(), body.Name)
+	room, ownerToken, err := handler.rooms.CreateRoom(request.Context(), service.RoomSettings{
+		Name:       body.Name,
+		Visibility: body.Visibility,
+		Slug:       body.Slug,
+
+		ReactionKinds:   body.ReactionKinds,
+		PrimaryReaction: body.PrimaryReaction,
+		Ranking:         body.Ranking,
+		Anonymous:       body.Anonymous,
+	})
 	if err != nil {
-		slog.Error("Failed to create room", "error", err)
-		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to create room")
 		return
 	}
 
 	type response struct {
-		ID        string `json:"id"`
-		Name      string `json:"name"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
+		roomResponse
+		OwnerToken string  `json:"owner_token"`
+		JoinCode   *string `json:"join_code"`
+	}
+
+	var joinCode *string
+	if room.JoinCode.Valid {
+		joinCode = &room.JoinCode.String
 	}
 
 	sendJSON(writer, response{
-		ID:        room.ID.String(),
-		Name:      room.Name,
-		CreatedAt: room.CreatedAt.Time.String(),
-		UpdatedAt: room.UpdatedAt.Time.String(),
+		roomResponse: newRoomResponse(room),
+		OwnerToken:   ownerToken,
+		JoinCode:     joinCode,
 	})
 }
 
 func (handler apiHandler) handleGetRooms(writer http.ResponseWriter, request *http.Request) {
-	rooms, err := handler.query.GetRooms(request.Context())
+	query := request.URL.Query()
+
+	filter := service.RoomFilter{
+		Search: query.Get("q"),
+		Status: query.Get("status"),
+		Sort:   query.Get("sort"),
+		Cursor: query.Get("cursor"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			http.Error(writer, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := handler.rooms.ListRooms(request.Context(), readActor(request), filter)
 	if err != nil {
-		slog.Error("Failed to get rooms", "error", err)
-		http.Error(writer, "Something went wrong while getting rooms", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to get rooms")
 		return
 	}
 
+	type room struct {
+		roomResponse
+		QuestionCount   int64 `json:"question_count"`
+		UnansweredCount int64 `json:"unanswered_count"`
+		SubscriberCount int   `json:"subscriber_count"`
+	}
+
 	type response struct {
-		List  []postgres.Room `json:"rooms"`
-		Total int             `json:"total"`
+		List       []room  `json:"rooms"`
+		Total      int     `json:"total"`
+		NextCursor *string `json:"next_cursor"`
+	}
+
+	list := make([]room, 0, len(page.Rooms))
+	for _, listing := range page.Rooms {
+		list = append(list, room{
+			roomResponse:    newRoomResponse(listing.Room),
+			QuestionCount:   listing.QuestionCount,
+			UnansweredCount: listing.UnansweredCount,
+			SubscriberCount: handler.subscriberCount(listing.Room.ID.String()),
+		})
+	}
+
+	var nextCursor *string
+	if page.NextCursor != "" {
+		nextCursor = &page.NextCursor
 	}
 
 	sendJSON(writer, response{
-		List:  rooms,
-		Total: len(rooms),
+		List:       list,
+		Total:      len(list),
+		NextCursor: nextCursor,
 	})
 }
 
-func (handler apiHandler) handleCreateRoomQuestion(writer http.ResponseWriter, request *http.Request) {
-	_, rawRoomID, roomID, ok := handler.readRoom(writer, request)
+func (handler apiHandler) handleGetRoom(writer http.ResponseWriter, request *http.Request) {
+	sendJSON(writer, newRoomResponse(roomFromContext(request)))
+}
+
+// handleRedirectToRoom sends short links such as /r/go-ama-k7qm to the room
+// page of the web client.
+func (handler apiHandler) handleRedirectToRoom(writer http.ResponseWriter, request *http.Request) {
+	room, _, _, ok := handler.readRoom(writer, request)
+
+	if !ok {
+		return
+	}
+
+	// Links to rooms of other organizations name them in the query, and links
+	// to private rooms carry the join code.
+	query := url.Values{}
+	for _, key := range []string{"org", "code"} {
+		if value := request.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	location := handler.webURL + "/room/" + url.PathEscape(room.Slug)
+	if len(query) > 0 {
+		location += "?" + query.Encode()
+	}
+
+	http.Redirect(writer, request, location, http.StatusFound)
+}
+
+func (handler apiHandler) handleDeleteRoom(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	purge := request.URL.Query().Get("purge") == "true"
+
+	if err := handler.rooms.DeleteRoom(request.Context(), readActor(request), roomID, purge); err != nil {
+		sendServiceError(writer, request, err, "Failed to delete room")
+		return
+	}
+
+	type response struct {
+		Room string `json:"room"`
+	}
+
+	sendJSON(writer, response{
+		Room: "Room deleted",
+	})
+
+	go handler.closeRoomSubscribers(rawRoomID, "room deleted")
+}
+
+func (handler apiHandler) handleUpdateRoomReactions(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
 
 	if !ok {
 		return
 	}
 
 	type _body struct {
-		Text string `json:"text"`
+		ReactionKinds   []string `json:"reaction_kinds"`
+		PrimaryReaction string   `json:"primary_reaction"`
 	}
+
 	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	room, err := handler.rooms.UpdateReactions(request.Context(), readActor(request), roomID, body.ReactionKinds, body.PrimaryReaction)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to update reactions")
+		return
+	}
+
+	sendJSON(writer, newRoomResponse(room))
+}
+
+func (handler apiHandler) handleUpdateRoomRanking(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		Ranking string `json:"ranking"`
+	}
+
+	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	room, err := handler.rooms.UpdateRanking(request.Context(), readActor(request), roomID, body.Ranking)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to update ranking")
+		return
+	}
+
+	sendJSON(writer, newRoomResponse(room))
+}
 
+func (handler apiHandler) handleUpdateRoomAnonymity(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		Anonymous bool `json:"anonymous"`
+	}
+
+	var body _body
 	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
 		http.Error(writer, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	question, err := handler.query.CreateQuestion(request.Context(), postgres.CreateQuestionParams{RoomID: roomID, Text: body.Text})
+	room, err := handler.rooms.UpdateAnonymity(request.Context(), readActor(request), roomID, body.Anonymous)
 	if err != nil {
-		slog.Error("Failed to create question", "error", err)
-		http.Error(writer, "Something went wrong while creating question", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to update anonymity")
 		return
 	}
 
+	sendJSON(writer, newRoomResponse(room))
+}
+
+func (handler apiHandler) handleGetAuditLog(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	entries, err := handler.rooms.GetAuditLog(request.Context(), readActor(request), roomID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to get audit log")
+		return
+	}
+
+	type entry struct {
+		ID        int64           `json:"id"`
+		Entity    string          `json:"entity"`
+		EntityID  string          `json:"entity_id"`
+		Action    string          `json:"action"`
+		Actor     string          `json:"actor"`
+		Changes   json.RawMessage `json:"changes"`
+		CreatedAt string          `json:"created_at"`
+	}
+
 	type response struct {
-		ID        string `json:"id"`
-		Text      string `json:"text"`
-		CreatedAt string `json:"created_at"`
-		UpdatedAt string `json:"updated_at"`
+		List  []entry `json:"list"`
+		Total int     `json:"total"`
+	}
+
+	list := make([]entry, 0, len(entries))
+	for _, item := range entries {
+		list = append(list, entry{
+			ID:        item.ID,
+			Entity:    item.Entity,
+			EntityID:  item.EntityID.String(),
+			Action:    item.Action,
+			Actor:     item.Actor,
+			Changes:   item.Changes,
+			CreatedAt: formatTimestamp(item.CreatedAt),
+		})
 	}
 
 	sendJSON(writer, response{
-		ID:        question.ID.String(),
-		Text:      question.Text,
-		CreatedAt: question.CreatedAt.Time.String(),
-		UpdatedAt: question.UpdatedAt.Time.String(),
+		List:  list,
+		Total: len(entries),
 	})
+}
+
+func (handler apiHandler) handleCreateRoomQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		Text       string `json:"text"`
+		AuthorName string `json:"author_name"`
+	}
+	var body _body
+
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	question, err := handler.questions.CreateQuestion(request.Context(), readActor(request), roomID, body.Text, body.AuthorName)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to create question")
+		return
+	}
+
+	sendJSON(writer, newQuestionResponse(question))
 
 	go handler.handleNotify(Notification{
 		Category: QuestionCreatedCategory,
 		Value: NotificationValue{
-			ID:    question.ID.String(),
-			Text:  body.Text,
-			Count: 0,
+			ID:         question.ID.String(),
+			Text:       question.Text,
+			Count:      0,
+			AuthorName: question.AuthorName.String,
 		},
 		RoomId: rawRoomID,
 	})
 }
 
 func (handler apiHandler) handleGetRoomQuestions(writer http.ResponseWriter, request *http.Request) {
-	_, _, roomID, ok := handler.readRoom(writer, request)
+	_, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	limit, ok := readIntQuery(writer, request, "limit")
+	if !ok {
+		return
+	}
+
+	offset, ok := readIntQuery(writer, request, "offset")
+	if !ok {
+		return
+	}
+
+	roomQuestions, err := handler.questions.GetRoomQuestions(request.Context(), roomID, limit, offset)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to get room questions")
+		return
+	}
+
+	type response struct {
+		List       []questionResponse `json:"list"`
+		Total      int                `json:"total"`
+		NextOffset *int               `json:"next_offset"`
+	}
+
+	// A full page may be followed by another one.
+	var nextOffset *int
+	if limit > 0 && len(roomQuestions) == limit {
+		next := offset + limit
+		nextOffset = &next
+	}
+
+	sendJSON(writer, response{
+		List:       newQuestionResponses(roomQuestions),
+		Total:      len(roomQuestions),
+		NextOffset: nextOffset,
+	})
+}
+
+func (handler apiHandler) handleSearchRoomQuestions(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
 
 	if !ok {
 		return
 	}
 
-	roomQuestions, err := handler.query.GetRoomQuestions(request.Context(), roomID)
+	var limit int
+	if rawLimit := request.URL.Query().Get("limit"); rawLimit != "" {
+		var err error
+		if limit, err = strconv.Atoi(rawLimit); err != nil {
+			http.Error(writer, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	matches, err := handler.questions.SearchQuestions(request.Context(), roomID, request.URL.Query().Get("q"), limit)
 	if err != nil {
-		slog.Error("Failed to get room questions", "error", err)
-		http.Error(writer, "Something went wrong while getting questions", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to search questions")
 		return
 	}
 
-	result := roomQuestions
-	if len(roomQuestions) == 0 {
-		result = []postgres.Question{}
+	// Snippets are HTML: the question text is escaped and the matches are
+	// wrapped in <mark>.
+	type match struct {
+		ID            string  `json:"id"`
+		Text          string  `json:"text"`
+		Snippet       string  `json:"snippet"`
+		Rank          float32 `json:"rank"`
+		ReactionCount int64   `json:"reaction_count"`
+		Answered      bool    `json:"answered"`
+		CreatedAt     string  `json:"created_at"`
 	}
 
 	type response struct {
-		List  []postgres.Question `json:"list"`
-		Total int                 `json:"total"`
+		List  []match `json:"list"`
+		Total int     `json:"total"`
+	}
+
+	list := make([]match, 0, len(matches))
+	for _, item := range matches {
+		list = append(list, match{
+			ID:            item.ID.String(),
+			Text:          item.Text,
+			Snippet:       item.Snippet,
+			Rank:          item.Rank,
+			ReactionCount: item.ReactionCount,
+			Answered:      item.Answered,
+			CreatedAt:     formatTimestamp(item.CreatedAt),
+		})
 	}
 
 	sendJSON(writer, response{
-		List:  result,
-		Total: len(roomQuestions),
+		List:  list,
+		Total: len(list),
 	})
 }
 
 func (handler apiHandler) handleGetRoomQuestion(writer http.ResponseWriter, request *http.Request) {
-	rawQuestionID := chi.URLParam(request, "question_id")
-	questionID, err := uuid.Parse(rawQuestionID)
-	questionExists := handler.checkIfQuestionExists(questionID, err, writer, request)
+	_, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	question, err := handler.questions.GetQuestion(request.Context(), roomID, questionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to get question")
+		return
+	}
+
+	sendJSON(writer, newQuestionResponse(question))
+}
+
+func (handler apiHandler) handleEditQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		Text string `json:"text"`
+	}
+	var body _body
+
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	question, err := handler.questions.EditQuestion(request.Context(), readActor(request), roomID, questionID, body.Text)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to edit question")
+		return
+	}
+
+	sendJSON(writer, newQuestionResponse(question))
+
+	go handler.handleNotify(Notification{
+		Category: QuestionEditedCategory,
+		Value: NotificationValue{
+			ID:    question.ID.String(),
+			Text:  question.Text,
+			Count: question.ReactionCount,
+		},
+		RoomId: rawRoomID,
+	})
+}
+
+func (handler apiHandler) handleGetQuestionEdits(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, questionID, ok := readQuestionID(writer, request)
 
-	if !questionExists {
+	if !ok {
 		return
 	}
 
-	question, err := handler.query.GetQuestion(request.Context(), questionID)
+	edits, err := handler.questions.GetQuestionEdits(request.Context(), roomID, questionID)
 	if err != nil {
-		slog.Error("Failed to get question", "error", err)
-		http.Error(writer, "Something went wrong while getting question", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to get question edits")
+		return
+	}
+
+	type edit struct {
+		Text     string `json:"text"`
+		EditedAt string `json:"edited_at"`
+	}
+
+	type response struct {
+		List  []edit `json:"list"`
+		Total int    `json:"total"`
+	}
+
+	list := make([]edit, 0, len(edits))
+	for _, item := range edits {
+		list = append(list, edit{
+			Text:     item.Text,
+			EditedAt: formatTimestamp(item.EditedAt),
+		})
+	}
+
+	sendJSON(writer, response{
+		List:  list,
+		Total: len(edits),
+	})
+}
+
+func (handler apiHandler) handleDeleteQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	purge := request.URL.Query().Get("purge") == "true"
+
+	if err := handler.questions.DeleteQuestion(request.Context(), readActor(request), roomID, questionID, purge); err != nil {
+		sendServiceError(writer, request, err, "Failed to delete question")
 		return
 	}
 
 	type response struct {
-		ID            string `json:"id"`
-		Text          string `json:"text"`
-		ReactionCount int64  `json:"reaction_count"`
-		Answered      bool   `json:"answered"`
-		CreatedAt     string `json:"created_at"`
-		UpdatedAt     string `json:"updated_at"`
+		Question string `json:"question"`
 	}
 
-	sendJSON(writer, response{ID: question.ID.String(),
-		Text:          question.Text,
-		ReactionCount: question.ReactionCount,
-		Answered:      question.Answered,
-		CreatedAt:     question.CreatedAt.Time.String(),
-		UpdatedAt:     question.UpdatedAt.Time.String(),
+	sendJSON(writer, response{
+		Question: "Question deleted",
+	})
+
+	go handler.handleNotify(Notification{
+		Category: QuestionDeletedCategory,
+		Value: NotificationValue{
+			ID:    questionID.String(),
+			Text:  "Question deleted",
+			Count: 0,
+		},
+		RoomId: rawRoomID,
 	})
 }
 
 func (handler apiHandler) handleReactToQuestion(writer http.ResponseWriter, request *http.Request) {
-	rawRoomID := chi.URLParam(request, "room_id")
-	rawQuestionID := chi.URLParam(request, "question_id")
-	questionID, err := uuid.Parse(rawQuestionID)
-	questionExists := handler.checkIfQuestionExists(questionID, err, writer, request)
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
 
-	if !questionExists {
+	if !ok {
 		return
 	}
 
+	// The kind is optional, reactions default to the room's primary kind.
 	type _body struct {
-		Reaction bool `json:"reaction"`
+		Kind string `json:"kind"`
 	}
 
 	var body _body
-	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
 		http.Error(writer, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	reactionCount, err := handler.query.ReactToQuestion(request.Context(), questionID)
+	question, err := handler.questions.React(request.Context(), roomID, questionID, body.Kind)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to react to question")
+		return
+	}
+
+	handler.sendReactions(writer, request, rawRoomID, QuestionReactionIncreaseCategory, "Reaction added", body.Kind, question)
+}
+
+func (handler apiHandler) handleRemoveReaction(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	kind := request.URL.Query().Get("kind")
+
+	question, err := handler.questions.RemoveReaction(request.Context(), roomID, questionID, kind)
 	if err != nil {
-		slog.Error("Failed to react to question", "error", err)
-		http.Error(writer, "Something went wrong while reacting to question", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to remove the reaction from question")
 		return
 	}
 
+	handler.sendReactions(writer, request, rawRoomID, QuestionReactionDecreaseCategory, "Reaction removed", kind, question)
+}
+
+// sendReactions answers a reaction change with the updated counts and tells
+// the room about it. The count of the notification stays the primary one.
+func (handler apiHandler) sendReactions(writer http.ResponseWriter, request *http.Request, rawRoomID, category, text, kind string, question service.QuestionDetails) {
+	if kind == "" {
+		kind = roomFromContext(request).PrimaryReaction
+	}
+
 	type response struct {
-		ReactionCount int64 `json:"reaction_count"`
+		ReactionCount int64             `json:"reaction_count"`
+		Reactions     service.Reactions `json:"reactions"`
 	}
 
 	sendJSON(writer, response{
-		ReactionCount: reactionCount,
+		ReactionCount: question.ReactionCount,
+		Reactions:     question.Reactions,
 	})
 
 	go handler.handleNotify(Notification{
-		Category: QuestionReactionIncreaseCategory,
+		Category: category,
 		Value: NotificationValue{
-			ID:    questionID.String(),
-			Text:  "Reaction added",
-			Count: reactionCount,
+			ID:        question.ID.String(),
+			Text:      text,
+			Count:     question.ReactionCount,
+			Kind:      kind,
+			Reactions: question.Reactions,
 		},
 		RoomId: rawRoomID,
 	})
 }
 
-func (handler apiHandler) handleRemoveReaction(writer http.ResponseWriter, request *http.Request) {
-	rawRoomID := chi.URLParam(request, "room_id")
-	rawQuestionID := chi.URLParam(request, "question_id")
-	questionID, err := uuid.Parse(rawQuestionID)
-	questionExists := handler.checkIfQuestionExists(questionID, err, writer, request)
+func (handler apiHandler) handleDownvoteQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
 
-	if !questionExists {
+	if !ok {
+		return
+	}
+
+	question, err := handler.questions.Downvote(request.Context(), roomID, questionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to downvote question")
+		return
+	}
+
+	handler.sendDownvotes(writer, rawRoomID, QuestionDownvoteIncreaseCategory, "Downvote added", question)
+}
+
+func (handler apiHandler) handleRemoveDownvote(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
 		return
 	}
 
-	reactionCount, err := handler.query.RemoveReactionFromQuestion(request.Context(), questionID)
+	question, err := handler.questions.RemoveDownvote(request.Context(), roomID, questionID)
 	if err != nil {
-		slog.Error("Failed to remove the reaction from question", "error", err)
-		http.Error(writer, "Something went wrong while removing react from question", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to remove the downvote from question")
 		return
 	}
 
+	handler.sendDownvotes(writer, rawRoomID, QuestionDownvoteDecreaseCategory, "Downvote removed", question)
+}
+
+// sendDownvotes is sendReactions for downvotes, whose notifications count the
+// downvotes.
+func (handler apiHandler) sendDownvotes(writer http.ResponseWriter, rawRoomID, category, text string, question service.QuestionDetails) {
 	type response struct {
 		ReactionCount int64 `json:"reaction_count"`
+		DownvoteCount int64 `json:"downvote_count"`
 	}
 
 	sendJSON(writer, response{
-		ReactionCount: reactionCount,
+		ReactionCount: question.ReactionCount,
+		DownvoteCount: question.DownvoteCount,
 	})
 
 	go handler.handleNotify(Notification{
-		Category: QuestionReactionDecreaseCategory,
+		Category: category,
 		Value: NotificationValue{
-			ID:    questionID.String(),
-			Text:  "Reaction removed",
-			Count: reactionCount,
+			ID:    question.ID.String(),
+			Text:  text,
+			Count: question.DownvoteCount,
 		},
 		RoomId: rawRoomID,
 	})
 }
 
 func (handler apiHandler) handleMarkQuestionAsAnswered(writer http.ResponseWriter, request *http.Request) {
-	rawRoomID := chi.URLParam(request, "room_id")
-	rawQuestionID := chi.URLParam(request, "question_id")
-	questionID, err := uuid.Parse(rawQuestionID)
-	questionExists := handler.checkIfQuestionExists(questionID, err, writer, request)
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
 
-	if !questionExists {
+	if !ok {
 		return
 	}
 
-	err = handler.query.MarkQuestionAsAnswered(request.Context(), questionID)
+	err := handler.questions.MarkAsAnswered(request.Context(), readActor(request), roomID, questionID)
 	if err != nil {
-		slog.Error("Failed to mark question as answered", "error", err)
-		http.Error(writer, "Something went wrong while marking question as answered", http.StatusInternalServerError)
+		sendServiceError(writer, request, err, "Failed to mark question as answered")
 		return
 	}
 
@@ -440,7 +986,7 @@ func (handler apiHandler) handleMarkQuestionAsAnswered(writer http.ResponseWrite
 	})
 
 	go handler.handleNotify(Notification{
-		Category: questionID.String(),
+		Category: QuestionAnsweredCategory,
 		Value: NotificationValue{
 			ID:    questionID.String(),
 			Text:  "Question marked as answered",
@@ -0,0 +1,1889 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/pedrogiorgetti/ama/go/internal/auth"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/memory"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
+)
+
+type testRoom struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	OwnerToken string `json:"owner_token"`
+}
+
+type testQuestion struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, apiHandler) {
+	t.Helper()
+
+	server, handler, _ := newTestServerWithStore(t)
+	return server, handler
+}
+
+func newTestServerWithStore(t *testing.T) (*httptest.Server, apiHandler, *memory.Store) {
+	t.Helper()
+
+	return newTestServerWithConfig(t, DefaultConfig())
+}
+
+func newTestServerWithConfig(t *testing.T, config Config) (*httptest.Server, apiHandler, *memory.Store) {
+	t.Helper()
+
+	store := memory.New()
+	handler := NewHandler(store, config).(apiHandler)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server, handler, store
+}
+
+// doJSON sends body encoded as JSON and decodes a successful response into
+// out, returning the status code.
+func doJSON(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+
+	return doJSONWithHeader(t, method, url, nil, body, out)
+}
+
+// doOwnerJSON is doJSON acting as the owner holding ownerToken.
+func doOwnerJSON(t *testing.T, method, url, ownerToken string, body any, out any) int {
+	t.Helper()
+
+	return doJSONWithHeader(t, method, url, http.Header{ownerTokenHeader: {ownerToken}}, body, out)
+}
+
+// doParticipantJSON is doJSON acting as the participant identified by
+// participantID.
+func doParticipantJSON(t *testing.T, method, url, participantID string, body any, out any) int {
+	t.Helper()
+
+	return doJSONWithHeader(t, method, url, http.Header{participantIDHeader: {participantID}}, body, out)
+}
+
+func doJSONWithHeader(t *testing.T, method, url string, header http.Header, body any, out any) int {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request, err := http.NewRequest(method, url, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if out != nil && response.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s %s: %v", method, url, err)
+		}
+	}
+
+	return response.StatusCode
+}
+
+func createTestRoom(t *testing.T, server *httptest.Server, name string) testRoom {
+	t.Helper()
+
+	var room testRoom
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", map[string]string{"name": name}, &room); status != http.StatusOK {
+		t.Fatalf("create room: status %d", status)
+	}
+
+	return room
+}
+
+func createTestQuestion(t *testing.T, server *httptest.Server, roomID, text string) testQuestion {
+	t.Helper()
+
+	var question testQuestion
+	url := server.URL + "/api/rooms/" + roomID + "/questions"
+	if status := doJSON(t, http.MethodPost, url, map[string]string{"text": text}, &question); status != http.StatusOK {
+		t.Fatalf("create question: status %d", status)
+	}
+
+	return question
+}
+
+func TestCreateAndListRooms(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	if room.Name != "Go AMA" || room.ID == "" || room.OwnerToken == "" {
+		t.Fatalf("unexpected room %+v", room)
+	}
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms", nil, &list); status != http.StatusOK {
+		t.Fatalf("list rooms: status %d", status)
+	}
+	if list.Total != 1 {
+		t.Fatalf("expected 1 room, got %d", list.Total)
+	}
+
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", map[string]string{"name": "  "}, nil); status != http.StatusBadRequest {
+		t.Fatalf("blank room name: expected 400, got %d", status)
+	}
+}
+
+func TestResponsesUseRFC3339AndSnakeCase(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	createTestQuestion(t, server, room.ID, "Generics?")
+
+	var rooms struct {
+		List []map[string]any `json:"rooms"`
+	}
+	doJSON(t, http.MethodGet, server.URL+"/api/rooms", nil, &rooms)
+
+	var questions struct {
+		List []map[string]any `json:"list"`
+	}
+	doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/questions", nil, &questions)
+
+	if len(rooms.List) != 1 || len(questions.List) != 1 {
+		t.Fatalf("unexpected listings %+v, %+v", rooms.List, questions.List)
+	}
+
+	for _, item := range []map[string]any{rooms.List[0], questions.List[0]} {
+		for _, key := range []string{"created_at", "updated_at"} {
+			value, _ := item[key].(string)
+			parsed, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil || parsed.Location() != time.UTC {
+				t.Fatalf("%s: expected an RFC 3339 UTC timestamp, got %q", key, value)
+			}
+		}
+
+		for key := range item {
+			if strings.ToLower(key) != key {
+				t.Fatalf("expected snake_case fields, got %q", key)
+			}
+		}
+	}
+
+	if closedAt, ok := rooms.List[0]["closed_at"]; !ok || closedAt != nil {
+		t.Fatalf("expected closed_at to be null, got %v", closedAt)
+	}
+	if _, leaked := rooms.List[0]["owner_token_hash"]; leaked {
+		t.Fatal("the room listing leaks the owner token hash")
+	}
+}
+
+func TestListRooms(t *testing.T) {
+	server, handler, store := newTestServerWithStore(t)
+
+	goRoom := createTestRoom(t, server, "Go AMA")
+	rustRoom := createTestRoom(t, server, "Rust AMA")
+	percentRoom := createTestRoom(t, server, "Go 100% AMA")
+	createTestQuestion(t, server, rustRoom.ID, "Borrow checker?")
+	createTestQuestion(t, server, rustRoom.ID, "Async?")
+	createTestQuestion(t, server, goRoom.ID, "Generics?")
+
+	if _, err := store.CloseRoom(context.Background(), postgres.CloseRoomParams{ID: uuid.MustParse(percentRoom.ID), OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+
+	subscribe(t, server, handler, rustRoom.ID)
+
+	type listing struct {
+		ID              string `json:"id"`
+		QuestionCount   int64  `json:"question_count"`
+		UnansweredCount int64  `json:"unanswered_count"`
+		SubscriberCount int    `json:"subscriber_count"`
+	}
+	type page struct {
+		Rooms      []listing `json:"rooms"`
+		NextCursor *string   `json:"next_cursor"`
+	}
+	list := func(query string) page {
+		t.Helper()
+
+		var result page
+		if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms?"+query, nil, &result); status != http.StatusOK {
+			t.Fatalf("list rooms?%s: status %d", query, status)
+		}
+
+		return result
+	}
+	ids := func(result page) []string {
+		var ids []string
+		for _, room := range result.Rooms {
+			ids = append(ids, room.ID)
+		}
+
+		return ids
+	}
+
+	if got := ids(list("")); !slices.Equal(got, []string{percentRoom.ID, rustRoom.ID, goRoom.ID}) {
+		t.Fatalf("expected the newest rooms first, got %v", got)
+	}
+	if got := ids(list("q=go")); !slices.Equal(got, []string{percentRoom.ID, goRoom.ID}) {
+		t.Fatalf("search: got %v", got)
+	}
+	if got := ids(list("q=100%25")); !slices.Equal(got, []string{percentRoom.ID}) {
+		t.Fatalf("search with a wildcard: got %v", got)
+	}
+	if got := ids(list("status=closed")); !slices.Equal(got, []string{percentRoom.ID}) {
+		t.Fatalf("closed rooms: got %v", got)
+	}
+
+	active := list("sort=active")
+	if got := ids(active); !slices.Equal(got, []string{rustRoom.ID, goRoom.ID, percentRoom.ID}) {
+		t.Fatalf("expected the most active rooms first, got %v", got)
+	}
+	if rust := active.Rooms[0]; rust.QuestionCount != 2 || rust.UnansweredCount != 2 || rust.SubscriberCount != 1 {
+		t.Fatalf("unexpected stats %+v", rust)
+	}
+
+	for _, sort := range []string{"newest", "active"} {
+		var paged []string
+		result := list("limit=1&sort=" + sort)
+		for {
+			paged = append(paged, ids(result)...)
+			if result.NextCursor == nil {
+				break
+			}
+			result = list("limit=1&sort=" + sort + "&cursor=" + *result.NextCursor)
+		}
+
+		if want := ids(list("sort=" + sort)); !slices.Equal(paged, want) {
+			t.Fatalf("%s: paging gave %v, expected %v", sort, paged, want)
+		}
+	}
+
+	for _, query := range []string{"limit=-1", "limit=101", "limit=abc", "status=archived", "sort=oldest", "cursor=nope"} {
+		if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms?"+query, nil, nil); status != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, status)
+		}
+	}
+}
+
+func TestListRoomsKeepsSettings(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	body := map[string]any{
+		"name":           "Go AMA",
+		"slug":           "go-ama",
+		"reaction_kinds": []string{"upvote", "heart"},
+		"ranking":        "hot",
+		"anonymous":      true,
+	}
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", body, nil); status != http.StatusOK {
+		t.Fatalf("create room: status %d", status)
+	}
+
+	type listing struct {
+		Slug            string   `json:"slug"`
+		Visibility      string   `json:"visibility"`
+		ReactionKinds   []string `json:"reaction_kinds"`
+		PrimaryReaction string   `json:"primary_reaction"`
+		Ranking         string   `json:"ranking"`
+		Anonymous       bool     `json:"anonymous"`
+	}
+	for _, sort := range []string{"newest", "active"} {
+		var page struct {
+			Rooms []listing `json:"rooms"`
+		}
+		if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms?sort="+sort, nil, &page); status != http.StatusOK || len(page.Rooms) != 1 {
+			t.Fatalf("%s: status %d, %d rooms", sort, status, len(page.Rooms))
+		}
+
+		room := page.Rooms[0]
+		if room.Slug != "go-ama" || room.Visibility != "public" || !slices.Equal(room.ReactionKinds, []string{"upvote", "heart"}) ||
+			room.PrimaryReaction != "upvote" || room.Ranking != "hot" || !room.Anonymous {
+			t.Fatalf("%s: expected the room settings in the listing, got %+v", sort, room)
+		}
+	}
+}
+
+func TestRoomVisibility(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	type createdRoom struct {
+		testRoom
+		Slug       string  `json:"slug"`
+		Visibility string  `json:"visibility"`
+		JoinCode   *string `json:"join_code"`
+	}
+
+	createRoom := func(name, visibility string) createdRoom {
+		t.Helper()
+
+		var room createdRoom
+		body := map[string]string{"name": name, "visibility": visibility}
+		if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", body, &room); status != http.StatusOK {
+			t.Fatalf("create %s room: status %d", visibility, status)
+		}
+
+		return room
+	}
+
+	public := createRoom("Public", "")
+	unlisted := createRoom("Unlisted", "unlisted")
+	private := createRoom("Private", "private")
+
+	if public.Visibility != "public" || public.JoinCode != nil {
+		t.Fatalf("expected a public room without join code, got %+v", public)
+	}
+	if private.JoinCode == nil || *private.JoinCode == "" {
+		t.Fatal("expected a join code for the private room")
+	}
+
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", map[string]string{"name": "Secret", "visibility": "secret"}, nil); status != http.StatusBadRequest {
+		t.Fatalf("unknown visibility: expected 400, got %d", status)
+	}
+
+	var listing struct {
+		Rooms []testRoom `json:"rooms"`
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms", nil, &listing); status != http.StatusOK {
+		t.Fatalf("list rooms: status %d", status)
+	}
+	if len(listing.Rooms) != 1 || listing.Rooms[0].ID != public.ID {
+		t.Fatalf("expected only the public room to be listed, got %+v", listing.Rooms)
+	}
+
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+unlisted.ID+"/questions", nil, nil); status != http.StatusOK {
+		t.Fatalf("unlisted room: expected 200, got %d", status)
+	}
+
+	questionsURL := server.URL + "/api/rooms/" + private.ID + "/questions"
+	withCode := func(participantID, code string) http.Header {
+		return http.Header{participantIDHeader: {participantID}, joinCodeHeader: {code}}
+	}
+
+	if status := doJSON(t, http.MethodGet, questionsURL, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("private room without code: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, questionsURL, withCode("alice", "WRONG-CODE"), nil, nil); status != http.StatusForbidden {
+		t.Fatalf("private room with wrong code: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodGet, questionsURL, private.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("private room as owner: expected 200, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, questionsURL, withCode("alice", strings.ToLower(*private.JoinCode)), map[string]string{"text": "Hi?"}, nil); status != http.StatusOK {
+		t.Fatalf("private room with code: expected 200, got %d", status)
+	}
+
+	// Entering with the code granted alice access for good, but not bob.
+	if status := doParticipantJSON(t, http.MethodGet, questionsURL, "alice", nil, nil); status != http.StatusOK {
+		t.Fatalf("granted participant: expected 200, got %d", status)
+	}
+	if status := doParticipantJSON(t, http.MethodGet, questionsURL, "bob", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("other participant: expected 403, got %d", status)
+	}
+
+	subscribeURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/subscribe/" + private.ID
+	_, response, err := websocket.DefaultDialer.Dial(subscribeURL, nil)
+	if err == nil || response.StatusCode != http.StatusForbidden {
+		t.Fatalf("subscribe without code: expected 403, got %v", err)
+	}
+
+	connection, _, err := websocket.DefaultDialer.Dial(subscribeURL+"?code="+*private.JoinCode, nil)
+	if err != nil {
+		t.Fatalf("subscribe with code: %v", err)
+	}
+	_ = connection.Close()
+
+	// Shared links keep the code through the redirect.
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err = client.Get(server.URL + "/r/" + private.ID + "?code=" + *private.JoinCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if location := "/room/" + private.Slug + "?code=" + *private.JoinCode; response.StatusCode != http.StatusFound || response.Header.Get("Location") != location {
+		t.Fatalf("redirect to a private room with code: expected a redirect to %q, got %d to %q", location, response.StatusCode, response.Header.Get("Location"))
+	}
+
+	response, err = client.Get(server.URL + "/r/" + private.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("redirect to a private room without code: expected 403, got %d", response.StatusCode)
+	}
+}
+
+func TestRoomSlugs(t *testing.T) {
+	config := DefaultConfig()
+	config.WebURL = "http://localhost:5173/"
+	server, handler, _ := newTestServerWithConfig(t, config)
+
+	type sluggedRoom struct {
+		testRoom
+		Slug string `json:"slug"`
+	}
+
+	var generated sluggedRoom
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", map[string]string{"name": "Launch Q&A!"}, &generated); status != http.StatusOK {
+		t.Fatalf("create room: status %d", status)
+	}
+	if !strings.HasPrefix(generated.Slug, "launch-q-a-") {
+		t.Fatalf("expected a slug generated from the name, got %q", generated.Slug)
+	}
+
+	var custom sluggedRoom
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", map[string]string{"name": "Go AMA", "slug": "go-ama"}, &custom); status != http.StatusOK {
+		t.Fatalf("create room with slug: status %d", status)
+	}
+
+	for slug, want := range map[string]int{
+		"go-ama":                               http.StatusConflict,
+		"Go AMA":                               http.StatusBadRequest,
+		"go--ama":                              http.StatusBadRequest,
+		"00000000-0000-0000-0000-000000000000": http.StatusBadRequest,
+	} {
+		if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", map[string]string{"name": "Go AMA", "slug": slug}, nil); status != want {
+			t.Fatalf("slug %q: expected %d, got %d", slug, want, status)
+		}
+	}
+
+	var found sluggedRoom
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/go-ama", nil, &found); status != http.StatusOK || found.ID != custom.ID {
+		t.Fatalf("get room by slug: status %d, room %+v", status, found)
+	}
+
+	// Subscribers are keyed by the room ID, whichever way the room is named.
+	connection := subscribe(t, server, handler, custom.ID)
+	question := createTestQuestion(t, server, "go-ama", "Generics?")
+	expectNotification(t, connection, QuestionCreatedCategory, question.ID)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	response, err := client.Get(server.URL + "/r/go-ama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusFound || response.Header.Get("Location") != "http://localhost:5173/room/go-ama" {
+		t.Fatalf("expected a redirect to the room page, got %d to %q", response.StatusCode, response.Header.Get("Location"))
+	}
+}
+
+func TestReactionKinds(t *testing.T) {
+	server, handler := newTestServer(t)
+
+	var room testRoom
+	body := map[string]any{"name": "Go AMA", "reaction_kinds": []string{"upvote", "me-too", "🎉"}}
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", body, &room); status != http.StatusOK {
+		t.Fatalf("create room: status %d", status)
+	}
+
+	for _, kinds := range [][]string{{"up vote"}, {"upvote", "upvote"}} {
+		body := map[string]any{"name": "Invalid", "reaction_kinds": kinds}
+		if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", body, nil); status != http.StatusBadRequest {
+			t.Fatalf("reaction kinds %q: expected 400, got %d", kinds, status)
+		}
+	}
+
+	question := createTestQuestion(t, server, room.ID, "Generics?")
+	reactURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + question.ID + "/react"
+	connection := subscribe(t, server, handler, room.ID)
+
+	type reactions struct {
+		ReactionCount int64            `json:"reaction_count"`
+		Reactions     map[string]int64 `json:"reactions"`
+	}
+
+	var result reactions
+	if status := doJSON(t, http.MethodPatch, reactURL, map[string]string{"kind": "me-too"}, &result); status != http.StatusOK {
+		t.Fatalf("react: status %d", status)
+	}
+	if result.ReactionCount != 0 || result.Reactions["me-too"] != 1 || result.Reactions["upvote"] != 0 {
+		t.Fatalf("expected only the me-too count to change, got %+v", result)
+	}
+
+	notification := expectNotification(t, connection, QuestionReactionIncreaseCategory, question.ID)
+	if notification.Value.Kind != "me-too" || notification.Value.Reactions["me-too"] != 1 {
+		t.Fatalf("expected the breakdown in the notification, got %+v", notification.Value)
+	}
+
+	// Reactions without a kind are of the primary one, which ranks questions.
+	if status := doJSON(t, http.MethodPatch, reactURL, nil, &result); status != http.StatusOK {
+		t.Fatalf("react: status %d", status)
+	}
+	if result.ReactionCount != 1 || result.Reactions["upvote"] != 1 {
+		t.Fatalf("expected the upvote to count, got %+v", result)
+	}
+	expectNotification(t, connection, QuestionReactionIncreaseCategory, question.ID)
+
+	if status := doJSON(t, http.MethodPatch, reactURL, map[string]string{"kind": "🚀"}, nil); status != http.StatusBadRequest {
+		t.Fatalf("unknown kind: expected 400, got %d", status)
+	}
+
+	for range 2 {
+		if status := doJSON(t, http.MethodDelete, reactURL+"?kind=me-too", nil, &result); status != http.StatusOK {
+			t.Fatalf("remove reaction: status %d", status)
+		}
+	}
+	if result.Reactions["me-too"] != 0 {
+		t.Fatalf("expected counts never to go below zero, got %+v", result)
+	}
+
+	// Switching the primary kind ranks the questions by it.
+	reactionsURL := server.URL + "/api/rooms/" + room.ID + "/reactions"
+	update := map[string]any{"reaction_kinds": []string{"upvote", "🎉"}, "primary_reaction": "🎉"}
+	if status := doJSON(t, http.MethodPut, reactionsURL, update, nil); status != http.StatusForbidden {
+		t.Fatalf("update reactions without owner token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPut, reactionsURL, room.OwnerToken, update, nil); status != http.StatusOK {
+		t.Fatalf("update reactions: status %d", status)
+	}
+
+	var listing struct {
+		List []reactions `json:"list"`
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/questions", nil, &listing); status != http.StatusOK {
+		t.Fatalf("list questions: status %d", status)
+	}
+	want := map[string]int64{"upvote": 1, "🎉": 0}
+	if len(listing.List) != 1 || listing.List[0].ReactionCount != 0 || !maps.Equal(listing.List[0].Reactions, want) {
+		t.Fatalf("expected the breakdown of the room's kinds, got %+v", listing.List)
+	}
+}
+
+func TestQuestionLifecycle(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Lifecycle")
+	question := createTestQuestion(t, server, room.ID, "How does it work?")
+	questionURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + question.ID
+
+	var reaction struct {
+		ReactionCount int64 `json:"reaction_count"`
+	}
+	for range 2 {
+		if status := doJSON(t, http.MethodPatch, questionURL+"/react", map[string]bool{"reaction": true}, &reaction); status != http.StatusOK {
+			t.Fatalf("react: status %d", status)
+		}
+	}
+	if reaction.ReactionCount != 2 {
+		t.Fatalf("expected 2 reactions, got %d", reaction.ReactionCount)
+	}
+
+	if status := doJSON(t, http.MethodDelete, questionURL+"/react", nil, &reaction); status != http.StatusOK {
+		t.Fatalf("remove reaction: status %d", status)
+	}
+	if reaction.ReactionCount != 1 {
+		t.Fatalf("expected 1 reaction, got %d", reaction.ReactionCount)
+	}
+
+	if status := doJSON(t, http.MethodPatch, questionURL+"/answers", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("answer without a token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPatch, questionURL+"/answers", room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("answer: status %d", status)
+	}
+
+	var fetched struct {
+		ReactionCount int64 `json:"reaction_count"`
+		Answered      bool  `json:"answered"`
+	}
+	if status := doJSON(t, http.MethodGet, questionURL, nil, &fetched); status != http.StatusOK {
+		t.Fatalf("get question: status %d", status)
+	}
+	if fetched.ReactionCount != 1 || !fetched.Answered {
+		t.Fatalf("unexpected question state %+v", fetched)
+	}
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/questions", nil, &list); status != http.StatusOK {
+		t.Fatalf("list questions: status %d", status)
+	}
+	if list.Total != 1 {
+		t.Fatalf("expected 1 question, got %d", list.Total)
+	}
+}
+
+func TestSearchQuestions(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	pricing := createTestQuestion(t, server, room.ID, "What about <b>pricing</b> for teams?")
+	createTestQuestion(t, server, room.ID, "Is there a free tier?")
+	other := createTestRoom(t, server, "Other")
+	createTestQuestion(t, server, other.ID, "And pricing here?")
+
+	var result struct {
+		List []struct {
+			ID      string `json:"id"`
+			Snippet string `json:"snippet"`
+		} `json:"list"`
+	}
+	searchURL := server.URL + "/api/rooms/" + room.ID + "/questions/search"
+	if status := doJSON(t, http.MethodGet, searchURL+"?q=pricing", nil, &result); status != http.StatusOK {
+		t.Fatalf("search: status %d", status)
+	}
+
+	if len(result.List) != 1 || result.List[0].ID != pricing.ID {
+		t.Fatalf("expected only the pricing question of the room, got %+v", result.List)
+	}
+	if snippet := result.List[0].Snippet; !strings.Contains(snippet, "<mark>pricing</mark>") || strings.Contains(snippet, "<b>") {
+		t.Fatalf("expected an escaped, highlighted snippet, got %q", snippet)
+	}
+
+	if status := doJSON(t, http.MethodGet, searchURL+"?q=", nil, nil); status != http.StatusBadRequest {
+		t.Fatalf("empty search: expected 400, got %d", status)
+	}
+}
+
+func TestQuestionErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Errors")
+	other := createTestRoom(t, server, "Other")
+	question := createTestQuestion(t, server, room.ID, "Where am I?")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+	}{
+		{"unknown slug", http.MethodGet, "/api/rooms/nope/questions", nil, http.StatusNotFound},
+		{"invalid slug", http.MethodGet, "/api/rooms/no_pe/questions", nil, http.StatusNotFound},
+		{"unknown room", http.MethodGet, "/api/rooms/00000000-0000-0000-0000-000000000000/questions", nil, http.StatusNotFound},
+		{"blank question", http.MethodPost, "/api/rooms/" + room.ID + "/questions", map[string]string{"text": ""}, http.StatusBadRequest},
+		{"invalid question id", http.MethodGet, "/api/rooms/" + room.ID + "/questions/nope", nil, http.StatusBadRequest},
+		{"question from another room", http.MethodGet, "/api/rooms/" + other.ID + "/questions/" + question.ID, nil, http.StatusNotFound},
+		{"react through another room", http.MethodPatch, "/api/rooms/" + other.ID + "/questions/" + question.ID + "/react", map[string]bool{"reaction": true}, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := doJSON(t, test.method, server.URL+test.path, test.body, nil); status != test.status {
+				t.Fatalf("expected %d, got %d", test.status, status)
+			}
+		})
+	}
+}
+
+func TestClosedRoomRejectsQuestions(t *testing.T) {
+	server, _, store := newTestServerWithStore(t)
+
+	room := createTestRoom(t, server, "Closed")
+	question := createTestQuestion(t, server, room.ID, "Just in time?")
+	if _, err := store.CloseRoom(context.Background(), postgres.CloseRoomParams{ID: uuid.MustParse(room.ID), OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+
+	url := server.URL + "/api/rooms/" + room.ID + "/questions"
+	if status := doJSON(t, http.MethodPost, url, map[string]string{"text": "Too late?"}, nil); status != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", status)
+	}
+
+	commentsURL := url + "/" + question.ID + "/comments"
+	if status := doJSON(t, http.MethodPost, commentsURL, map[string]string{"text": "Too late?"}, nil); status != http.StatusConflict {
+		t.Fatalf("comment: expected 409, got %d", status)
+	}
+}
+
+func TestExportRoom(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Export")
+	quiet := createTestQuestion(t, server, room.ID, "Quiet question")
+	popular := createTestQuestion(t, server, room.ID, "Popular question")
+	doJSON(t, http.MethodPatch, server.URL+"/api/rooms/"+room.ID+"/questions/"+popular.ID+"/react", map[string]bool{"reaction": true}, nil)
+
+	var document struct {
+		Questions []testQuestion `json:"questions"`
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/export?format=json", nil, &document); status != http.StatusOK {
+		t.Fatalf("export: status %d", status)
+	}
+	if len(document.Questions) != 2 || document.Questions[0].ID != popular.ID || document.Questions[1].ID != quiet.ID {
+		t.Fatalf("expected questions ordered by popularity, got %+v", document.Questions)
+	}
+
+	response, err := http.Get(server.URL + "/api/rooms/" + room.ID + "/export?format=csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/export?format=xml", nil, nil); status != http.StatusBadRequest {
+		t.Fatalf("unknown format: expected 400, got %d", status)
+	}
+}
+
+func TestImportRoom(t *testing.T) {
+	server, handler := newTestServer(t)
+
+	room := createTestRoom(t, server, "Import")
+	connection := subscribe(t, server, handler, room.ID)
+	importURL := server.URL + "/api/rooms/" + room.ID + "/import"
+
+	request, err := http.NewRequest(http.MethodPost, importURL, strings.NewReader("text,answered\nFirst,false\n,false\nThird,maybe\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Type", "text/csv")
+	request.Header.Set(ownerTokenHeader, room.OwnerToken)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rejected struct {
+		Errors []struct {
+			Row int `json:"row"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&rejected); err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusUnprocessableEntity || len(rejected.Errors) != 2 || rejected.Errors[0].Row != 3 || rejected.Errors[1].Row != 4 {
+		t.Fatalf("expected rows 3 and 4 to be rejected, got %d %+v", response.StatusCode, rejected)
+	}
+
+	document := map[string]any{"questions": []map[string]any{
+		{"text": "Imported one", "reaction_count": 4},
+		{"text": "Imported two", "answered": true},
+	}}
+
+	if status := doJSON(t, http.MethodPost, importURL, document, nil); status != http.StatusForbidden {
+		t.Fatalf("import without a token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPost, importURL, "not-the-token", document, nil); status != http.StatusForbidden {
+		t.Fatalf("import with the wrong token: expected 403, got %d", status)
+	}
+
+	var imported struct {
+		Imported int64 `json:"imported"`
+	}
+	if status := doOwnerJSON(t, http.MethodPost, importURL, room.OwnerToken, document, &imported); status != http.StatusOK {
+		t.Fatalf("import: status %d", status)
+	}
+	if imported.Imported != 2 {
+		t.Fatalf("expected 2 imported questions, got %d", imported.Imported)
+	}
+
+	expectNotification(t, connection, QuestionsImportedCategory, room.ID)
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/questions", nil, &list)
+	if list.Total != 2 {
+		t.Fatalf("expected only the valid import to be stored, got %d questions", list.Total)
+	}
+}
+
+func TestDeleteRoom(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	roomURL := server.URL + "/api/rooms/" + room.ID
+
+	if status := doJSON(t, http.MethodDelete, roomURL, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("delete without a token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodDelete, roomURL, "not-the-token", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("delete with the wrong token: expected 403, got %d", status)
+	}
+
+	if status := doOwnerJSON(t, http.MethodDelete, roomURL, room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete: status %d", status)
+	}
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	doJSON(t, http.MethodGet, server.URL+"/api/rooms", nil, &list)
+	if list.Total != 0 {
+		t.Fatalf("expected the deleted room to be hidden, got %d rooms", list.Total)
+	}
+	if status := doJSON(t, http.MethodGet, roomURL+"/questions", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("questions of a deleted room: expected 404, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodDelete, roomURL+"?purge=true", room.OwnerToken, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("purging a soft-deleted room: expected 404, got %d", status)
+	}
+
+	purged := createTestRoom(t, server, "Purged")
+	if status := doOwnerJSON(t, http.MethodDelete, server.URL+"/api/rooms/"+purged.ID+"?purge=true", purged.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("purge: status %d", status)
+	}
+}
+
+func TestDeleteQuestion(t *testing.T) {
+	server, handler := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	connection := subscribe(t, server, handler, room.ID)
+
+	question := createTestQuestion(t, server, room.ID, "Generics?")
+	expectNotification(t, connection, QuestionCreatedCategory, question.ID)
+	questionURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + question.ID
+
+	if status := doJSON(t, http.MethodDelete, questionURL, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("delete without a token: expected 403, got %d", status)
+	}
+
+	if status := doOwnerJSON(t, http.MethodDelete, questionURL, room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete: status %d", status)
+	}
+	expectNotification(t, connection, QuestionDeletedCategory, question.ID)
+
+	if status := doJSON(t, http.MethodGet, questionURL, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("deleted question: expected 404, got %d", status)
+	}
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/questions", nil, &list)
+	if list.Total != 0 {
+		t.Fatalf("expected the deleted question to be hidden, got %d questions", list.Total)
+	}
+
+	purged := createTestQuestion(t, server, room.ID, "Iterators?")
+	expectNotification(t, connection, QuestionCreatedCategory, purged.ID)
+	purgeURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + purged.ID + "?purge=true"
+	if status := doOwnerJSON(t, http.MethodDelete, purgeURL, room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("purge: status %d", status)
+	}
+	expectNotification(t, connection, QuestionDeletedCategory, purged.ID)
+}
+
+func TestDeleteRoomClosesSubscribers(t *testing.T) {
+	server, handler := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	connection := subscribe(t, server, handler, room.ID)
+
+	if status := doOwnerJSON(t, http.MethodDelete, server.URL+"/api/rooms/"+room.ID, room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete: status %d", status)
+	}
+
+	_ = connection.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := connection.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("expected the connection to be closed, got %v", err)
+	}
+}
+
+func TestRanking(t *testing.T) {
+	server, handler := newTestServer(t)
+	room := createTestRoom(t, server, "Go AMA")
+	questionsURL := server.URL + "/api/rooms/" + room.ID + "/questions"
+
+	// Many votes with a fair ratio against a few unanimous ones.
+	popular := createTestQuestion(t, server, room.ID, "Generics?")
+	unanimous := createTestQuestion(t, server, room.ID, "Iterators?")
+	quiet := createTestQuestion(t, server, room.ID, "Modules?")
+
+	vote := func(question testQuestion, action string, times int) {
+		t.Helper()
+
+		for range times {
+			if status := doJSON(t, http.MethodPatch, questionsURL+"/"+question.ID+"/"+action, nil, nil); status != http.StatusOK {
+				t.Fatalf("%s: status %d", action, status)
+			}
+		}
+	}
+	vote(popular, "react", 10)
+	vote(popular, "downvote", 6)
+	vote(unanimous, "react", 3)
+	vote(quiet, "downvote", 1)
+
+	connection := subscribe(t, server, handler, room.ID)
+	var counts struct {
+		ReactionCount int64 `json:"reaction_count"`
+		DownvoteCount int64 `json:"downvote_count"`
+	}
+	for range 2 {
+		if status := doJSON(t, http.MethodDelete, questionsURL+"/"+quiet.ID+"/downvote", nil, &counts); status != http.StatusOK {
+			t.Fatalf("remove downvote: status %d", status)
+		}
+	}
+	if counts.DownvoteCount != 0 {
+		t.Fatalf("expected downvotes never to go below zero, got %+v", counts)
+	}
+	notification := expectNotification(t, connection, QuestionDownvoteDecreaseCategory, quiet.ID)
+	if notification.Value.Count != 0 {
+		t.Fatalf("expected the downvote count in the notification, got %+v", notification.Value)
+	}
+
+	type listing struct {
+		List []struct {
+			ID            string `json:"id"`
+			DownvoteCount int64  `json:"downvote_count"`
+		} `json:"list"`
+		NextOffset *int `json:"next_offset"`
+	}
+	expectOrder := func(query string, want ...testQuestion) listing {
+		t.Helper()
+
+		var result listing
+		if status := doJSON(t, http.MethodGet, questionsURL+query, nil, &result); status != http.StatusOK {
+			t.Fatalf("list questions: status %d", status)
+		}
+		if len(result.List) != len(want) {
+			t.Fatalf("expected %d questions, got %+v", len(want), result.List)
+		}
+		for i, question := range want {
+			if result.List[i].ID != question.ID {
+				t.Fatalf("expected %q at %d, got %+v", question.Text, i, result.List)
+			}
+		}
+
+		return result
+	}
+
+	page := expectOrder("?limit=2", popular, unanimous)
+	if page.List[0].DownvoteCount != 6 || page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Fatalf("expected the downvotes and the next offset, got %+v", page)
+	}
+	if page := expectOrder("?limit=2&offset=2", quiet); page.NextOffset != nil {
+		t.Fatalf("expected no next offset on the last page, got %d", *page.NextOffset)
+	}
+
+	rankingURL := server.URL + "/api/rooms/" + room.ID + "/ranking"
+	if status := doJSON(t, http.MethodPut, rankingURL, map[string]string{"ranking": "wilson"}, nil); status != http.StatusForbidden {
+		t.Fatalf("update ranking without owner token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPut, rankingURL, room.OwnerToken, map[string]string{"ranking": "newest"}, nil); status != http.StatusBadRequest {
+		t.Fatalf("unknown ranking: expected 400, got %d", status)
+	}
+
+	var updated struct {
+		Ranking string `json:"ranking"`
+	}
+	if status := doOwnerJSON(t, http.MethodPut, rankingURL, room.OwnerToken, map[string]string{"ranking": "wilson"}, &updated); status != http.StatusOK || updated.Ranking != "wilson" {
+		t.Fatalf("update ranking: status %d, %+v", status, updated)
+	}
+	expectOrder("", unanimous, popular, quiet)
+
+	// Questions of the same age keep their score order when hot.
+	if status := doOwnerJSON(t, http.MethodPut, rankingURL, room.OwnerToken, map[string]string{"ranking": "hot"}, nil); status != http.StatusOK {
+		t.Fatalf("update ranking: status %d", status)
+	}
+	expectOrder("", popular, unanimous, quiet)
+
+	for _, query := range []string{"?limit=-1", "?limit=abc", "?offset=-1"} {
+		if status := doJSON(t, http.MethodGet, questionsURL+query, nil, nil); status != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, status)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	server, handler := newTestServer(t)
+	room := createTestRoom(t, server, "Go AMA")
+	question := createTestQuestion(t, server, room.ID, "Generics?")
+	commentsURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + question.ID + "/comments"
+	connection := subscribe(t, server, handler, room.ID)
+
+	type comment struct {
+		ID         string `json:"id"`
+		QuestionID string `json:"question_id"`
+		Text       string `json:"text"`
+	}
+
+	var first comment
+	if status := doParticipantJSON(t, http.MethodPost, commentsURL, "alice", map[string]string{"text": "  Especially for maps  "}, &first); status != http.StatusOK {
+		t.Fatalf("create comment: status %d", status)
+	}
+	if first.Text != "Especially for maps" || first.QuestionID != question.ID {
+		t.Fatalf("unexpected comment %+v", first)
+	}
+	notification := expectNotification(t, connection, CommentCreatedCategory, first.ID)
+	if notification.Value.QuestionID != question.ID {
+		t.Fatalf("expected the question in the notification, got %+v", notification.Value)
+	}
+
+	var second comment
+	if status := doParticipantJSON(t, http.MethodPost, commentsURL, "bob", map[string]string{"text": "And slices"}, &second); status != http.StatusOK {
+		t.Fatalf("create comment: status %d", status)
+	}
+	expectNotification(t, connection, CommentCreatedCategory, second.ID)
+
+	if status := doJSON(t, http.MethodPost, commentsURL, map[string]string{"text": " "}, nil); status != http.StatusBadRequest {
+		t.Fatalf("empty comment: expected 400, got %d", status)
+	}
+
+	var listing struct {
+		List  []comment `json:"list"`
+		Total int       `json:"total"`
+	}
+	if status := doJSON(t, http.MethodGet, commentsURL, nil, &listing); status != http.StatusOK {
+		t.Fatalf("list comments: status %d", status)
+	}
+	if listing.Total != 2 || listing.List[0].ID != first.ID || listing.List[1].ID != second.ID {
+		t.Fatalf("expected the comments oldest first, got %+v", listing)
+	}
+
+	var questions struct {
+		List []struct {
+			CommentCount int64 `json:"comment_count"`
+		} `json:"list"`
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID+"/questions", nil, &questions); status != http.StatusOK {
+		t.Fatalf("list questions: status %d", status)
+	}
+	if len(questions.List) != 1 || questions.List[0].CommentCount != 2 {
+		t.Fatalf("expected the comment count in the listing, got %+v", questions.List)
+	}
+
+	// Only the author and the room owner can delete a comment.
+	if status := doParticipantJSON(t, http.MethodDelete, commentsURL+"/"+first.ID, "bob", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("delete someone else's comment: expected 403, got %d", status)
+	}
+	if status := doParticipantJSON(t, http.MethodDelete, commentsURL+"/"+first.ID, "alice", nil, nil); status != http.StatusOK {
+		t.Fatalf("delete own comment: status %d", status)
+	}
+	expectNotification(t, connection, CommentDeletedCategory, first.ID)
+	if status := doOwnerJSON(t, http.MethodDelete, commentsURL+"/"+second.ID, room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete comment as owner: status %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodDelete, commentsURL+"/"+second.ID, room.OwnerToken, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("delete deleted comment: expected 404, got %d", status)
+	}
+
+	// Comments can't be reached through another question.
+	other := createTestQuestion(t, server, room.ID, "Iterators?")
+	var third comment
+	if status := doJSON(t, http.MethodPost, commentsURL, map[string]string{"text": "Still?"}, &third); status != http.StatusOK {
+		t.Fatalf("create comment: status %d", status)
+	}
+	otherURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + other.ID + "/comments/" + third.ID
+	if status := doOwnerJSON(t, http.MethodDelete, otherURL, room.OwnerToken, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("delete through another question: expected 404, got %d", status)
+	}
+}
+
+func TestSpotlight(t *testing.T) {
+	server, handler, store := newTestServerWithStore(t)
+	room := createTestRoom(t, server, "Go AMA")
+	first := createTestQuestion(t, server, room.ID, "Generics?")
+	second := createTestQuestion(t, server, room.ID, "Iterators?")
+	spotlightURL := server.URL + "/api/rooms/" + room.ID + "/spotlight"
+	connection := subscribe(t, server, handler, room.ID)
+
+	type spotlight struct {
+		SpotlightQuestionID *string `json:"spotlight_question_id"`
+		SpotlightedAt       *string `json:"spotlighted_at"`
+	}
+
+	if status := doJSON(t, http.MethodPut, spotlightURL, map[string]string{"question_id": first.ID}, nil); status != http.StatusForbidden {
+		t.Fatalf("spotlight without owner token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPut, spotlightURL, room.OwnerToken, map[string]string{"question_id": uuid.NewString()}, nil); status != http.StatusNotFound {
+		t.Fatalf("spotlight unknown question: expected 404, got %d", status)
+	}
+
+	for _, question := range []testQuestion{first, second} {
+		var result spotlight
+		if status := doOwnerJSON(t, http.MethodPut, spotlightURL, room.OwnerToken, map[string]string{"question_id": question.ID}, &result); status != http.StatusOK {
+			t.Fatalf("spotlight: status %d", status)
+		}
+		if result.SpotlightQuestionID == nil || *result.SpotlightQuestionID != question.ID || result.SpotlightedAt == nil {
+			t.Fatalf("expected %q in the spotlight, got %+v", question.Text, result)
+		}
+
+		notification := expectNotification(t, connection, QuestionSpotlightedCategory, question.ID)
+		if notification.Value.Text != question.Text {
+			t.Fatalf("expected the question in the notification, got %+v", notification.Value)
+		}
+	}
+
+	var result spotlight
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID, nil, &result); status != http.StatusOK {
+		t.Fatalf("get room: status %d", status)
+	}
+	if result.SpotlightQuestionID == nil || *result.SpotlightQuestionID != second.ID {
+		t.Fatalf("expected the room to show the spotlight, got %+v", result)
+	}
+
+	// A deleted question leaves the spotlight.
+	if status := doOwnerJSON(t, http.MethodDelete, server.URL+"/api/rooms/"+room.ID+"/questions/"+second.ID, room.OwnerToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete question: status %d", status)
+	}
+	expectNotification(t, connection, QuestionDeletedCategory, second.ID)
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+room.ID, nil, &result); status != http.StatusOK || result.SpotlightQuestionID != nil {
+		t.Fatalf("expected no spotlight, got status %d, %+v", status, result)
+	}
+
+	if status := doOwnerJSON(t, http.MethodPut, spotlightURL, room.OwnerToken, map[string]string{"question_id": first.ID}, nil); status != http.StatusOK {
+		t.Fatalf("spotlight: status %d", status)
+	}
+	expectNotification(t, connection, QuestionSpotlightedCategory, first.ID)
+	if status := doOwnerJSON(t, http.MethodDelete, spotlightURL, room.OwnerToken, nil, &result); status != http.StatusOK || result.SpotlightQuestionID != nil || result.SpotlightedAt != nil {
+		t.Fatalf("clear spotlight: status %d, %+v", status, result)
+	}
+	expectNotification(t, connection, SpotlightClearedCategory, room.ID)
+
+	if _, err := store.CloseRoom(context.Background(), postgres.CloseRoomParams{ID: uuid.MustParse(room.ID), OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+	if status := doOwnerJSON(t, http.MethodPut, spotlightURL, room.OwnerToken, map[string]string{"question_id": first.ID}, nil); status != http.StatusConflict {
+		t.Fatalf("spotlight in a closed room: expected 409, got %d", status)
+	}
+}
+
+func TestPinning(t *testing.T) {
+	server, handler := newTestServer(t)
+	room := createTestRoom(t, server, "Go AMA")
+	questionsURL := server.URL + "/api/rooms/" + room.ID + "/questions"
+
+	popular := createTestQuestion(t, server, room.ID, "Generics?")
+	first := createTestQuestion(t, server, room.ID, "Iterators?")
+	second := createTestQuestion(t, server, room.ID, "Modules?")
+	if status := doJSON(t, http.MethodPatch, questionsURL+"/"+popular.ID+"/react", nil, nil); status != http.StatusOK {
+		t.Fatalf("react: status %d", status)
+	}
+
+	connection := subscribe(t, server, handler, room.ID)
+
+	if status := doJSON(t, http.MethodPatch, questionsURL+"/"+first.ID+"/pin", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("pin without owner token: expected 403, got %d", status)
+	}
+
+	for _, question := range []testQuestion{first, second} {
+		var pinned struct {
+			PinnedAt *string `json:"pinned_at"`
+		}
+		if status := doOwnerJSON(t, http.MethodPatch, questionsURL+"/"+question.ID+"/pin", room.OwnerToken, nil, &pinned); status != http.StatusOK || pinned.PinnedAt == nil {
+			t.Fatalf("pin: status %d, %+v", status, pinned)
+		}
+		expectNotification(t, connection, QuestionPinnedCategory, question.ID)
+	}
+
+	expectOrder := func(want ...testQuestion) {
+		t.Helper()
+
+		var listing struct {
+			List []testQuestion `json:"list"`
+		}
+		if status := doJSON(t, http.MethodGet, questionsURL, nil, &listing); status != http.StatusOK {
+			t.Fatalf("list questions: status %d", status)
+		}
+		if len(listing.List) != len(want) {
+			t.Fatalf("expected %d questions, got %+v", len(want), listing.List)
+		}
+		for i, question := range want {
+			if listing.List[i].ID != question.ID {
+				t.Fatalf("expected %q at %d, got %+v", question.Text, i, listing.List)
+			}
+		}
+	}
+
+	// Pins come first in the order they were pinned, whatever the votes say.
+	expectOrder(first, second, popular)
+
+	pinsURL := server.URL + "/api/rooms/" + room.ID + "/pins"
+	for _, ids := range [][]string{{second.ID}, {second.ID, second.ID}, {second.ID, popular.ID}} {
+		if status := doOwnerJSON(t, http.MethodPut, pinsURL, room.OwnerToken, map[string][]string{"question_ids": ids}, nil); status != http.StatusBadRequest {
+			t.Fatalf("reorder %v: expected 400, got %d", ids, status)
+		}
+	}
+	reorder := map[string][]string{"question_ids": {second.ID, first.ID}}
+	if status := doJSON(t, http.MethodPut, pinsURL, reorder, nil); status != http.StatusForbidden {
+		t.Fatalf("reorder without owner token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPut, pinsURL, room.OwnerToken, reorder, nil); status != http.StatusOK {
+		t.Fatalf("reorder: status %d", status)
+	}
+	expectNotification(t, connection, PinsReorderedCategory, room.ID)
+	expectOrder(second, first, popular)
+
+	var unpinned struct {
+		PinnedAt *string `json:"pinned_at"`
+	}
+	if status := doOwnerJSON(t, http.MethodDelete, questionsURL+"/"+second.ID+"/pin", room.OwnerToken, nil, &unpinned); status != http.StatusOK || unpinned.PinnedAt != nil {
+		t.Fatalf("unpin: status %d, %+v", status, unpinned)
+	}
+	expectNotification(t, connection, QuestionUnpinnedCategory, second.ID)
+	expectOrder(first, popular, second)
+}
+
+func TestPolls(t *testing.T) {
+	server, handler := newTestServer(t)
+	room := createTestRoom(t, server, "Go AMA")
+	pollsURL := server.URL + "/api/rooms/" + room.ID + "/polls"
+	connection := subscribe(t, server, handler, room.ID)
+
+	type poll struct {
+		ID      string `json:"id"`
+		Status  string `json:"status"`
+		Options []struct {
+			ID        string `json:"id"`
+			Text      string `json:"text"`
+			VoteCount int64  `json:"vote_count"`
+		} `json:"options"`
+		TotalVotes int64 `json:"total_votes"`
+	}
+
+	body := map[string]any{"question": "Tabs or spaces?", "options": []string{"Tabs", " Spaces "}}
+	if status := doJSON(t, http.MethodPost, pollsURL, body, nil); status != http.StatusForbidden {
+		t.Fatalf("create poll without owner token: expected 403, got %d", status)
+	}
+	for _, options := range [][]string{{"Tabs"}, {"Tabs", "Tabs"}, {"Tabs", ""}} {
+		invalid := map[string]any{"question": "Tabs or spaces?", "options": options}
+		if status := doOwnerJSON(t, http.MethodPost, pollsURL, room.OwnerToken, invalid, nil); status != http.StatusBadRequest {
+			t.Fatalf("options %q: expected 400, got %d", options, status)
+		}
+	}
+
+	var created poll
+	if status := doOwnerJSON(t, http.MethodPost, pollsURL, room.OwnerToken, body, &created); status != http.StatusOK {
+		t.Fatalf("create poll: status %d", status)
+	}
+	if created.Status != "open" || len(created.Options) != 2 || created.Options[1].Text != "Spaces" {
+		t.Fatalf("unexpected poll %+v", created)
+	}
+	expectNotification(t, connection, PollOpenedCategory, created.ID)
+
+	voteURL := pollsURL + "/" + created.ID + "/vote"
+	tabs, spaces := created.Options[0].ID, created.Options[1].ID
+
+	if status := doJSON(t, http.MethodPatch, voteURL, map[string]string{"option_id": tabs}, nil); status != http.StatusBadRequest {
+		t.Fatalf("vote without participant ID: expected 400, got %d", status)
+	}
+	if status := doParticipantJSON(t, http.MethodPatch, voteURL, "alice", map[string]string{"option_id": uuid.NewString()}, nil); status != http.StatusBadRequest {
+		t.Fatalf("vote for another poll's option: expected 400, got %d", status)
+	}
+
+	// A second vote moves the participant's vote instead of adding one.
+	for _, vote := range []struct{ participant, option string }{{"alice", tabs}, {"bob", tabs}, {"alice", spaces}} {
+		var result poll
+		if status := doParticipantJSON(t, http.MethodPatch, voteURL, vote.participant, map[string]string{"option_id": vote.option}, &result); status != http.StatusOK {
+			t.Fatalf("vote: status %d", status)
+		}
+
+		notification := expectNotification(t, connection, PollResultsCategory, created.ID)
+		if notification.Value.Poll == nil || notification.Value.Poll.TotalVotes != result.TotalVotes {
+			t.Fatalf("expected the results in the notification, got %+v", notification.Value)
+		}
+	}
+
+	var results poll
+	if status := doJSON(t, http.MethodGet, pollsURL+"/"+created.ID, nil, &results); status != http.StatusOK {
+		t.Fatalf("get poll: status %d", status)
+	}
+	if results.TotalVotes != 2 || results.Options[0].VoteCount != 1 || results.Options[1].VoteCount != 1 {
+		t.Fatalf("expected one vote per participant, got %+v", results)
+	}
+
+	if status := doJSON(t, http.MethodPatch, pollsURL+"/"+created.ID+"/close", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("close poll without owner token: expected 403, got %d", status)
+	}
+	if status := doOwnerJSON(t, http.MethodPatch, pollsURL+"/"+created.ID+"/close", room.OwnerToken, nil, &results); status != http.StatusOK || results.Status != "closed" {
+		t.Fatalf("close poll: status %d, %+v", status, results)
+	}
+	expectNotification(t, connection, PollClosedCategory, created.ID)
+	if status := doParticipantJSON(t, http.MethodPatch, voteURL, "carol", map[string]string{"option_id": tabs}, nil); status != http.StatusConflict {
+		t.Fatalf("vote in a closed poll: expected 409, got %d", status)
+	}
+
+	if status := doOwnerJSON(t, http.MethodPatch, pollsURL+"/"+created.ID+"/open", room.OwnerToken, nil, &results); status != http.StatusOK || results.Status != "open" {
+		t.Fatalf("reopen poll: status %d, %+v", status, results)
+	}
+	expectNotification(t, connection, PollOpenedCategory, created.ID)
+
+	var listing struct {
+		List  []poll `json:"list"`
+		Total int    `json:"total"`
+	}
+	if status := doJSON(t, http.MethodGet, pollsURL, nil, &listing); status != http.StatusOK {
+		t.Fatalf("list polls: status %d", status)
+	}
+	if listing.Total != 1 || listing.List[0].TotalVotes != 2 {
+		t.Fatalf("expected the poll with its results, got %+v", listing)
+	}
+
+	other := createTestRoom(t, server, "Other")
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/"+other.ID+"/polls/"+created.ID, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("poll through another room: expected 404, got %d", status)
+	}
+}
+
+func TestAuthorNames(t *testing.T) {
+	server, handler := newTestServer(t)
+	room := createTestRoom(t, server, "Go AMA")
+	roomURL := server.URL + "/api/rooms/" + room.ID
+	questionsURL := roomURL + "/questions"
+	connection := subscribe(t, server, handler, room.ID)
+
+	type question struct {
+		ID         string  `json:"id"`
+		AuthorName *string `json:"author_name"`
+	}
+
+	long := map[string]string{"text": "Who am I?", "author_name": strings.Repeat("a", 65)}
+	if status := doJSON(t, http.MethodPost, questionsURL, long, nil); status != http.StatusBadRequest {
+		t.Fatalf("long author name: expected 400, got %d", status)
+	}
+
+	var named question
+	body := map[string]string{"text": "Why Go?", "author_name": "  Ana  "}
+	if status := doJSON(t, http.MethodPost, questionsURL, body, &named); status != http.StatusOK {
+		t.Fatalf("create named question: status %d", status)
+	}
+	if named.AuthorName == nil || *named.AuthorName != "Ana" {
+		t.Fatalf("expected author Ana, got %v", named.AuthorName)
+	}
+	if notification := expectNotification(t, connection, QuestionCreatedCategory, named.ID); notification.Value.AuthorName != "Ana" {
+		t.Fatalf("expected the event to carry the author, got %+v", notification.Value)
+	}
+
+	var anonymous question
+	if status := doJSON(t, http.MethodPost, questionsURL, map[string]string{"text": "Why not Rust?"}, &anonymous); status != http.StatusOK {
+		t.Fatalf("create anonymous question: status %d", status)
+	}
+	if anonymous.AuthorName != nil {
+		t.Fatalf("expected no author, got %q", *anonymous.AuthorName)
+	}
+	if notification := expectNotification(t, connection, QuestionCreatedCategory, anonymous.ID); notification.Value.AuthorName != "" {
+		t.Fatalf("expected no author in the event, got %+v", notification.Value)
+	}
+
+	anonymity := map[string]bool{"anonymous": true}
+	if status := doJSON(t, http.MethodPut, roomURL+"/anonymity", anonymity, nil); status != http.StatusForbidden {
+		t.Fatalf("update anonymity without owner token: expected 403, got %d", status)
+	}
+
+	var updated struct {
+		Anonymous bool `json:"anonymous"`
+	}
+	if status := doOwnerJSON(t, http.MethodPut, roomURL+"/anonymity", room.OwnerToken, anonymity, &updated); status != http.StatusOK {
+		t.Fatalf("update anonymity: status %d", status)
+	}
+	if !updated.Anonymous {
+		t.Fatal("expected the room to be anonymous")
+	}
+
+	var forced question
+	body = map[string]string{"text": "Who wrote this?", "author_name": "Ana"}
+	if status := doJSON(t, http.MethodPost, questionsURL, body, &forced); status != http.StatusOK {
+		t.Fatalf("create question in anonymous room: status %d", status)
+	}
+	if forced.AuthorName != nil {
+		t.Fatalf("expected the anonymous room to drop the author, got %q", *forced.AuthorName)
+	}
+	if notification := expectNotification(t, connection, QuestionCreatedCategory, forced.ID); notification.Value.AuthorName != "" {
+		t.Fatalf("expected no author in the event, got %+v", notification.Value)
+	}
+
+	// Names given before the room turned anonymous are hidden too.
+	var shown question
+	if status := doJSON(t, http.MethodGet, questionsURL+"/"+named.ID, nil, &shown); status != http.StatusOK {
+		t.Fatalf("get question: status %d", status)
+	}
+	if shown.AuthorName != nil {
+		t.Fatalf("expected the author to be hidden, got %q", *shown.AuthorName)
+	}
+
+	if status := doOwnerJSON(t, http.MethodPut, roomURL+"/anonymity", room.OwnerToken, map[string]bool{"anonymous": false}, nil); status != http.StatusOK {
+		t.Fatalf("update anonymity: status %d", status)
+	}
+	if status := doJSON(t, http.MethodGet, questionsURL+"/"+named.ID, nil, &shown); status != http.StatusOK {
+		t.Fatalf("get question: status %d", status)
+	}
+	if shown.AuthorName == nil || *shown.AuthorName != "Ana" {
+		t.Fatalf("expected author Ana again, got %v", shown.AuthorName)
+	}
+}
+
+func TestMultibyteLengthLimits(t *testing.T) {
+	server, _ := newTestServer(t)
+	room := createTestRoom(t, server, strings.Repeat("é", 255))
+	questionsURL := server.URL + "/api/rooms/" + room.ID + "/questions"
+
+	atLimit := map[string]string{"text": strings.Repeat("ç", 255), "author_name": strings.Repeat("ã", 64)}
+	if status := doJSON(t, http.MethodPost, questionsURL, atLimit, nil); status != http.StatusOK {
+		t.Fatalf("multibyte text at the limit: expected 200, got %d", status)
+	}
+
+	tooLong := map[string]string{"text": strings.Repeat("ç", 256)}
+	if status := doJSON(t, http.MethodPost, questionsURL, tooLong, nil); status != http.StatusBadRequest {
+		t.Fatalf("multibyte text over the limit: expected 400, got %d", status)
+	}
+
+	longName := map[string]string{"name": strings.Repeat("é", 256)}
+	if status := doJSON(t, http.MethodPost, server.URL+"/api/rooms", longName, nil); status != http.StatusBadRequest {
+		t.Fatalf("multibyte room name over the limit: expected 400, got %d", status)
+	}
+}
+
+// createTestAPIKey creates an API key with the scopes and returns its secret.
+func createTestAPIKey(t *testing.T, store *memory.Store, organizationID uuid.UUID, scopes ...string) string {
+	t.Helper()
+
+	key, err := auth.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := postgres.CreateAPIKeyParams{OrganizationID: organizationID, Name: "bot", KeyHash: auth.HashToken(key), Scopes: scopes}
+	if _, err := store.CreateAPIKey(context.Background(), params); err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestOrganizations(t *testing.T) {
+	server, handler, store := newTestServerWithStore(t)
+	ctx := context.Background()
+
+	organization, err := store.CreateOrganization(ctx, postgres.CreateOrganizationParams{Name: "Platform", Slug: "platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := createTestAPIKey(t, store, organization.ID, service.ScopeRoomsWrite)
+	bearer := http.Header{"Authorization": {"Bearer " + key}}
+	platform := http.Header{organizationHeader: {"platform"}}
+
+	var room struct {
+		testRoom
+		JoinCode string `json:"join_code"`
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, server.URL+"/api/rooms", bearer, map[string]string{"name": "Platform AMA", "slug": "ama"}, &room); status != http.StatusOK {
+		t.Fatalf("create room with a key: status %d", status)
+	}
+	// Outside the default organization even public rooms get a join code.
+	if room.JoinCode == "" {
+		t.Fatal("expected a join code for a room of another organization")
+	}
+	withCode := http.Header{organizationHeader: {"platform"}, joinCodeHeader: {room.JoinCode}}
+	roomURL := server.URL + "/api/rooms/" + room.ID
+	defaultRoom := createTestRoom(t, server, "Default AMA")
+
+	// The default organization can't see the room, even by its ID or slug.
+	if status := doJSON(t, http.MethodGet, roomURL, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("get room outside its organization: expected 404, got %d", status)
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/ama", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("get room by slug outside its organization: expected 404, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms/"+defaultRoom.ID, platform, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("get default room from another organization: expected 404, got %d", status)
+	}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/subscribe/" + room.ID
+	if _, response, err := websocket.DefaultDialer.Dial(url, nil); err == nil || response.StatusCode != http.StatusNotFound {
+		t.Fatalf("subscribe outside the organization: expected 404, got %v", err)
+	}
+
+	var list struct {
+		Total int `json:"total"`
+	}
+	for _, header := range []http.Header{nil, bearer} {
+		if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms", header, nil, &list); status != http.StatusOK {
+			t.Fatalf("list rooms: status %d", status)
+		}
+		if list.Total != 1 {
+			t.Fatalf("expected 1 room in the organization, got %d", list.Total)
+		}
+	}
+	// Knowing the organization's name isn't enough to list its rooms.
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms", platform, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("list rooms of another organization without its key: expected 403, got %d", status)
+	}
+
+	// Nor to get into its rooms, public or private, without their join code.
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms/ama", platform, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("public room of another organization without code: expected 403, got %d", status)
+	}
+	if status := doJSON(t, http.MethodGet, roomURL+"?org=platform", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("public room of another organization with the org parameter alone: expected 403, got %d", status)
+	}
+	if _, response, err := websocket.DefaultDialer.Dial(url+"?org=platform", nil); err == nil || response.StatusCode != http.StatusForbidden {
+		t.Fatalf("subscribe to a room of another organization without code: expected 403, got %v", err)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, roomURL+"/questions", platform, map[string]string{"text": "Generics?"}, nil); status != http.StatusForbidden {
+		t.Fatalf("ask in a room of another organization without code: expected 403, got %d", status)
+	}
+
+	var private struct {
+		testRoom
+		JoinCode string `json:"join_code"`
+	}
+	body := map[string]string{"name": "Platform retro", "slug": "retro", "visibility": "private"}
+	if status := doJSONWithHeader(t, http.MethodPost, server.URL+"/api/rooms", bearer, body, &private); status != http.StatusOK {
+		t.Fatalf("create private room with a key: status %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms/retro", platform, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("private room of another organization without code: expected 403, got %d", status)
+	}
+	privateURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/subscribe/" + private.ID + "?org=platform"
+	if _, response, err := websocket.DefaultDialer.Dial(privateURL, nil); err == nil || response.StatusCode != http.StatusForbidden {
+		t.Fatalf("subscribe to a private room of another organization without code: expected 403, got %v", err)
+	}
+	if status := doJSON(t, http.MethodGet, server.URL+"/api/rooms/retro?org=platform&code="+private.JoinCode, nil, nil); status != http.StatusOK {
+		t.Fatalf("private room of another organization with its code: status %d", status)
+	}
+
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms/ama", withCode, nil, nil); status != http.StatusOK {
+		t.Fatalf("get room by slug within its organization: status %d", status)
+	}
+	if status := doJSON(t, http.MethodGet, roomURL+"?org="+organization.ID.String()+"&code="+room.JoinCode, nil, nil); status != http.StatusOK {
+		t.Fatalf("get room with the org parameter: status %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, roomURL, bearer, nil, nil); status != http.StatusOK {
+		t.Fatalf("get room with the key: status %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms", http.Header{organizationHeader: {"nowhere"}}, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("unknown organization: expected 404, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, server.URL+"/api/rooms", http.Header{"Authorization": {"Bearer nope"}}, nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("unknown key: expected 401, got %d", status)
+	}
+
+	connection, _, err := websocket.DefaultDialer.Dial(url+"?org=platform&code="+room.JoinCode, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = connection.Close() })
+
+	// The key owns every room of its organization.
+	if status := doJSONWithHeader(t, http.MethodPost, roomURL+"/questions", withCode, map[string]string{"text": "Generics?"}, nil); status != http.StatusOK {
+		t.Fatalf("create question: status %d", status)
+	}
+	ranking := map[string]string{"ranking": "hot"}
+	if status := doJSONWithHeader(t, http.MethodPut, roomURL+"/ranking", withCode, ranking, nil); status != http.StatusForbidden {
+		t.Fatalf("update ranking without the key: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPut, roomURL+"/ranking", bearer, ranking, nil); status != http.StatusOK {
+		t.Fatalf("update ranking with the key: status %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodDelete, server.URL+"/api/rooms/"+defaultRoom.ID, bearer, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("delete a room of another organization: expected 404, got %d", status)
+	}
+
+	handler.mutex.Lock()
+	subscribers := len(handler.subscribers[room.ID])
+	handler.mutex.Unlock()
+	if subscribers != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", subscribers)
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	server, _, store := newTestServerWithStore(t)
+	ctx := context.Background()
+
+	bearer := func(key string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + key}}
+	}
+	readOnly := bearer(createTestAPIKey(t, store, db.DefaultOrganizationID, service.ScopeReadOnly))
+	rooms := bearer(createTestAPIKey(t, store, db.DefaultOrganizationID, service.ScopeRoomsWrite))
+	moderator := bearer(createTestAPIKey(t, store, db.DefaultOrganizationID, service.ScopeQuestionsModerate))
+
+	var room testRoom
+	if status := doJSONWithHeader(t, http.MethodPost, server.URL+"/api/rooms", readOnly, map[string]string{"name": "Go AMA"}, nil); status != http.StatusForbidden {
+		t.Fatalf("create room with a read-only key: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, server.URL+"/api/rooms", moderator, map[string]string{"name": "Go AMA"}, nil); status != http.StatusForbidden {
+		t.Fatalf("create room without rooms:write: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, server.URL+"/api/rooms", rooms, map[string]string{"name": "Go AMA"}, &room); status != http.StatusOK {
+		t.Fatalf("create room with rooms:write: status %d", status)
+	}
+	roomURL := server.URL + "/api/rooms/" + room.ID
+
+	if status := doJSONWithHeader(t, http.MethodGet, roomURL, readOnly, nil, nil); status != http.StatusOK {
+		t.Fatalf("get room with a read-only key: status %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, roomURL+"/questions", readOnly, map[string]string{"text": "Generics?"}, nil); status != http.StatusForbidden {
+		t.Fatalf("create question with a read-only key: expected 403, got %d", status)
+	}
+
+	var question testQuestion
+	if status := doJSONWithHeader(t, http.MethodPost, roomURL+"/questions", rooms, map[string]string{"text": "Generics?"}, &question); status != http.StatusOK {
+		t.Fatalf("create question with a key: status %d", status)
+	}
+	answerURL := roomURL + "/questions/" + question.ID + "/answers"
+	if status := doJSONWithHeader(t, http.MethodPatch, answerURL, rooms, map[string]string{"answer": "Yes"}, nil); status != http.StatusForbidden {
+		t.Fatalf("answer without questions:moderate: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPatch, answerURL, moderator, map[string]string{"answer": "Yes"}, nil); status != http.StatusOK {
+		t.Fatalf("answer with questions:moderate: status %d", status)
+	}
+
+	// Going without any credentials doesn't get around the scopes.
+	if status := doJSON(t, http.MethodPatch, answerURL, nil, nil); status != http.StatusForbidden {
+		t.Fatalf("answer without credentials: expected 403, got %d", status)
+	}
+	document := map[string]any{"questions": []map[string]any{{"text": "fake", "reaction_count": 9999, "answered": true}}}
+	if status := doJSON(t, http.MethodPost, roomURL+"/import", document, nil); status != http.StatusForbidden {
+		t.Fatalf("import without credentials: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, roomURL+"/import", moderator, document, nil); status != http.StatusForbidden {
+		t.Fatalf("import without rooms:write: expected 403, got %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPost, roomURL+"/import", rooms, document, nil); status != http.StatusOK {
+		t.Fatalf("import with rooms:write: status %d", status)
+	}
+	if status := doJSONWithHeader(t, http.MethodPut, roomURL+"/ranking", moderator, map[string]string{"ranking": "hot"}, nil); status != http.StatusForbidden {
+		t.Fatalf("update ranking without rooms:write: expected 403, got %d", status)
+	}
+
+	keys, err := store.GetOrganizationAPIKeys(ctx, db.DefaultOrganizationID)
+	if err != nil || len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %+v, %v", keys, err)
+	}
+	for _, key := range keys {
+		if !key.LastUsedAt.Valid {
+			t.Fatalf("expected key %s to have been used", key.Scopes)
+		}
+	}
+
+	revoked, err := store.RevokeAPIKey(ctx, postgres.RevokeAPIKeyParams{ID: keys[1].ID, OrganizationID: db.DefaultOrganizationID})
+	if err != nil || !revoked.RevokedAt.Valid {
+		t.Fatalf("expected the key to be revoked, got %+v, %v", revoked, err)
+	}
+	if status := doJSONWithHeader(t, http.MethodGet, roomURL, rooms, nil, nil); status != http.StatusUnauthorized {
+		t.Fatalf("revoked key: expected 401, got %d", status)
+	}
+}
+
+func TestEditQuestion(t *testing.T) {
+	server, handler := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	connection := subscribe(t, server, handler, room.ID)
+
+	var question testQuestion
+	questionsURL := server.URL + "/api/rooms/" + room.ID + "/questions"
+	if status := doParticipantJSON(t, http.MethodPost, questionsURL, "author", map[string]string{"text": "Genrics?"}, &question); status != http.StatusOK {
+		t.Fatalf("create question: status %d", status)
+	}
+	expectNotification(t, connection, QuestionCreatedCategory, question.ID)
+	questionURL := questionsURL + "/" + question.ID
+
+	edit := map[string]string{"text": "Generics?"}
+	if status := doJSON(t, http.MethodPatch, questionURL, edit, nil); status != http.StatusForbidden {
+		t.Fatalf("edit without an identity: expected 403, got %d", status)
+	}
+	if status := doParticipantJSON(t, http.MethodPatch, questionURL, "someone-else", edit, nil); status != http.StatusForbidden {
+		t.Fatalf("edit by someone else: expected 403, got %d", status)
+	}
+	if status := doParticipantJSON(t, http.MethodPatch, questionURL, "author", map[string]string{"text": " "}, nil); status != http.StatusBadRequest {
+		t.Fatalf("blank edit: expected 400, got %d", status)
+	}
+
+	var edited testQuestion
+	if status := doParticipantJSON(t, http.MethodPatch, questionURL, "author", edit, &edited); status != http.StatusOK {
+		t.Fatalf("edit: status %d", status)
+	}
+	if edited.Text != "Generics?" {
+		t.Fatalf("expected the new text, got %q", edited.Text)
+	}
+	expectNotification(t, connection, QuestionEditedCategory, question.ID)
+
+	var history struct {
+		List []struct {
+			Text string `json:"text"`
+		} `json:"list"`
+	}
+	doJSON(t, http.MethodGet, questionURL+"/edits", nil, &history)
+	if len(history.List) != 1 || history.List[0].Text != "Genrics?" {
+		t.Fatalf("expected the previous text in the history, got %+v", history.List)
+	}
+
+	if status := doJSON(t, http.MethodPatch, questionURL+"/react", map[string]bool{"reaction": true}, nil); status != http.StatusOK {
+		t.Fatalf("react: status %d", status)
+	}
+	if status := doParticipantJSON(t, http.MethodPatch, questionURL, "author", edit, nil); status != http.StatusConflict {
+		t.Fatalf("edit after a reaction: expected 409, got %d", status)
+	}
+}
+
+func TestEditQuestionAfterWindow(t *testing.T) {
+	server, _, _ := newTestServerWithConfig(t, Config{QuestionEditWindow: 0})
+
+	room := createTestRoom(t, server, "Go AMA")
+
+	var question testQuestion
+	questionsURL := server.URL + "/api/rooms/" + room.ID + "/questions"
+	doParticipantJSON(t, http.MethodPost, questionsURL, "author", map[string]string{"text": "Genrics?"}, &question)
+
+	edit := map[string]string{"text": "Generics?"}
+	if status := doParticipantJSON(t, http.MethodPatch, questionsURL+"/"+question.ID, "author", edit, nil); status != http.StatusConflict {
+		t.Fatalf("edit after the window: expected 409, got %d", status)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	room := createTestRoom(t, server, "Go AMA")
+	roomURL := server.URL + "/api/rooms/" + room.ID
+
+	var question testQuestion
+	doParticipantJSON(t, http.MethodPost, roomURL+"/questions", "author", map[string]string{"text": "Generics?"}, &question)
+	questionURL := roomURL + "/questions/" + question.ID
+
+	doJSON(t, http.MethodPatch, questionURL+"/react", map[string]bool{"reaction": true}, nil)
+	doOwnerJSON(t, http.MethodPatch, questionURL+"/answers", room.OwnerToken, nil, nil)
+	doOwnerJSON(t, http.MethodDelete, questionURL, room.OwnerToken, nil, nil)
+
+	if status := doJSON(t, http.MethodGet, roomURL+"/audit", nil, nil); status != http.StatusForbidden {
+		t.Fatalf("audit log without a token: expected 403, got %d", status)
+	}
+
+	var log struct {
+		List []struct {
+			Entity   string                     `json:"entity"`
+			EntityID string                     `json:"entity_id"`
+			Action   string                     `json:"action"`
+			Actor    string                     `json:"actor"`
+			Changes  map[string]json.RawMessage `json:"changes"`
+		} `json:"list"`
+	}
+	if status := doOwnerJSON(t, http.MethodGet, roomURL+"/audit", room.OwnerToken, nil, &log); status != http.StatusOK {
+		t.Fatalf("audit log: status %d", status)
+	}
+
+	// The reaction isn't recorded, everything else is, in order.
+	expected := []struct{ entity, action, change string }{
+		{"room", "insert", "name"},
+		{"question", "insert", "text"},
+		{"question", "update", "answered"},
+		{"question", "update", "deleted_at"},
+	}
+	if len(log.List) != len(expected) {
+		t.Fatalf("expected %d entries, got %+v", len(expected), log.List)
+	}
+	for index, want := range expected {
+		entry := log.List[index]
+		if entry.Entity != want.entity || entry.Action != want.action || entry.Changes[want.change] == nil {
+			t.Fatalf("entry %d: expected a %s %s changing %s, got %+v", index, want.entity, want.action, want.change, entry)
+		}
+		if _, leaked := entry.Changes["owner_token_hash"]; leaked {
+			t.Fatalf("entry %d leaks the owner token hash", index)
+		}
+	}
+
+	if log.List[0].Actor != "owner" || !strings.HasPrefix(log.List[1].Actor, "participant:") || log.List[2].Actor != "owner" {
+		t.Fatalf("unexpected actors %+v", log.List)
+	}
+}
+
+func TestSubscribeReceivesNotifications(t *testing.T) {
+	server, handler := newTestServer(t)
+
+	room := createTestRoom(t, server, "Live")
+	connection := subscribe(t, server, handler, room.ID)
+
+	question := createTestQuestion(t, server, room.ID, "Is this live?")
+	expectNotification(t, connection, QuestionCreatedCategory, question.ID)
+
+	questionURL := server.URL + "/api/rooms/" + room.ID + "/questions/" + question.ID
+	doJSON(t, http.MethodPatch, questionURL+"/react", map[string]bool{"reaction": true}, nil)
+	expectNotification(t, connection, QuestionReactionIncreaseCategory, question.ID)
+
+	doOwnerJSON(t, http.MethodPatch, questionURL+"/answers", room.OwnerToken, nil, nil)
+	expectNotification(t, connection, QuestionAnsweredCategory, question.ID)
+}
+
+func TestSubscribeUnknownRoom(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/subscribe/00000000-0000-0000-0000-000000000000"
+	_, response, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatal("expected the handshake to fail")
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", response.StatusCode)
+	}
+}
+
+// subscribe opens a WebSocket for the room and waits until the handler has
+// registered it, so no notification sent afterwards can be missed.
+func subscribe(t *testing.T, server *httptest.Server, handler apiHandler, roomID string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/subscribe/" + roomID
+	connection, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = connection.Close() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		handler.mutex.Lock()
+		registered := len(handler.subscribers[roomID])
+		handler.mutex.Unlock()
+
+		if registered > 0 {
+			return connection
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatal("subscriber was never registered")
+	return nil
+}
+
+func expectNotification(t *testing.T, connection *websocket.Conn, category, id string) Notification {
+	t.Helper()
+
+	_ = connection.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var notification Notification
+	if err := connection.ReadJSON(&notification); err != nil {
+		t.Fatalf("waiting for %s: %v", category, err)
+	}
+
+	if notification.Category != category || notification.Value.ID != id {
+		t.Fatalf("expected %s for %s, got %+v", category, id, notification)
+	}
+
+	return notification
+}
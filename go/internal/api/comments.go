@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (handler apiHandler) handleGetComments(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	comments, err := handler.questions.GetComments(request.Context(), roomID, questionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to get comments")
+		return
+	}
+
+	type response struct {
+		List  []commentResponse `json:"list"`
+		Total int               `json:"total"`
+	}
+
+	list := make([]commentResponse, 0, len(comments))
+	for _, comment := range comments {
+		list = append(list, newCommentResponse(comment))
+	}
+
+	sendJSON(writer, response{
+		List:  list,
+		Total: len(comments),
+	})
+}
+
+func (handler apiHandler) handleCreateComment(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		Text string `json:"text"`
+	}
+
+	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := handler.questions.CreateComment(request.Context(), readActor(request), roomID, questionID, body.Text)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to create comment")
+		return
+	}
+
+	sendJSON(writer, newCommentResponse(comment))
+
+	go handler.handleNotify(Notification{
+		Category: CommentCreatedCategory,
+		Value: NotificationValue{
+			ID:         comment.ID.String(),
+			Text:       comment.Text,
+			QuestionID: questionID.String(),
+		},
+		RoomId: rawRoomID,
+	})
+}
+
+func (handler apiHandler) handleDeleteComment(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	commentID, err := uuid.Parse(chi.URLParam(request, "comment_id"))
+	if err != nil {
+		http.Error(writer, "Invalid comment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := handler.questions.DeleteComment(request.Context(), readActor(request), roomID, questionID, commentID); err != nil {
+		sendServiceError(writer, request, err, "Failed to delete comment")
+		return
+	}
+
+	type response struct {
+		Comment string `json:"comment"`
+	}
+
+	sendJSON(writer, response{
+		Comment: "Comment deleted",
+	})
+
+	go handler.handleNotify(Notification{
+		Category: CommentDeletedCategory,
+		Value: NotificationValue{
+			ID:         commentID.String(),
+			Text:       "Comment deleted",
+			QuestionID: questionID.String(),
+		},
+		RoomId: rawRoomID,
+	})
+}
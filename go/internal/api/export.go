@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/pedrogiorgetti/ama/go/internal/export"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
+)
+
+// maxImportBodySize caps the size of an uploaded import document.
+const maxImportBodySize = 5 << 20
+
+func (handler apiHandler) handleExportRoom(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	format, err := export.ParseFormat(request.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	room, questions, err := handler.rooms.Export(request.Context(), roomID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to export room")
+		return
+	}
+
+	writer.Header().Set("Content-Type", format.ContentType())
+	writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="room-%s.%s"`, room.ID, format))
+
+	if err := export.Write(writer, format, room, questions); err != nil {
+		loggerFromContext(request.Context()).Warn("Failed to stream room export", "error", err)
+	}
+}
+
+func (handler apiHandler) handleImportRoom(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	rawFormat := request.URL.Query().Get("format")
+	if mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type")); rawFormat == "" && mediaType == "text/csv" {
+		rawFormat = string(export.CSV)
+	}
+
+	format, err := export.ParseFormat(rawFormat)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := export.Parse(http.MaxBytesReader(writer, request.Body, maxImportBodySize), format)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	imported, err := handler.questions.Import(request.Context(), readActor(request), roomID, rows)
+
+	var importErr *service.ImportError
+	if errors.As(err, &importErr) {
+		type response struct {
+			Errors []service.RowError `json:"errors"`
+		}
+
+		sendJSONWithStatus(writer, http.StatusUnprocessableEntity, response{Errors: importErr.Rows})
+		return
+	}
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to import questions")
+		return
+	}
+
+	type response struct {
+		Imported int64 `json:"imported"`
+	}
+
+	sendJSON(writer, response{
+		Imported: imported,
+	})
+
+	go handler.handleNotify(Notification{
+		Category: QuestionsImportedCategory,
+		Value: NotificationValue{
+			ID:    rawRoomID,
+			Text:  fmt.Sprintf("%d questions imported", imported),
+			Count: imported,
+		},
+		RoomId: rawRoomID,
+	})
+}
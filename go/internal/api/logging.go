@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type loggerContextKey struct{}
+
+// requestLogger logs every request as a single structured line once it is
+// served and stores a request-scoped logger on the context, so anything the
+// handlers log carries the same request ID.
+func requestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			start := time.Now()
+
+			scoped := logger.With(
+				"request_id", middleware.GetReqID(request.Context()),
+				"method", request.Method,
+				"path", request.URL.Path,
+			)
+
+			wrapped := middleware.NewWrapResponseWriter(writer, request.ProtoMajor)
+			ctx := context.WithValue(request.Context(), loggerContextKey{}, scoped)
+
+			next.ServeHTTP(wrapped, request.WithContext(ctx))
+
+			status := wrapped.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			attributes := []any{
+				"status", status,
+				"bytes", wrapped.BytesWritten(),
+				"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+				"remote_addr", request.RemoteAddr,
+			}
+
+			if routeContext := chi.RouteContext(request.Context()); routeContext != nil {
+				attributes = append(attributes, "route", routeContext.RoutePattern())
+
+				if roomID := routeContext.URLParam("room_id"); roomID != "" {
+					attributes = append(attributes, "room_id", roomID)
+				}
+			}
+
+			scoped.Info("Request completed", attributes...)
+		})
+	}
+}
+
+// loggerFromContext returns the request-scoped logger set by requestLogger,
+// falling back to the default logger outside of a request.
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
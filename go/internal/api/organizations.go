@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
+)
+
+type apiKeyContextKey struct{}
+
+// resolveOrganization scopes the request to an organization: the one of the
+// API key in the Authorization header, or else the one named by the
+// X-Organization header or the org query parameter, which websockets have to
+// use. Requests naming none act within the default organization.
+//
+// Naming an organization is no credential, only where to look. Outside the
+// default organization every room asks for its join code, unless the request
+// carries the organization's API key or the room's owner token, and listing
+// the rooms takes the API key.
+func (handler apiHandler) resolveOrganization(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+
+		if key, ok := readBearerToken(request); ok {
+			apiKey, err := handler.organizations.Authenticate(ctx, key)
+			if err != nil {
+				sendServiceError(writer, request, err, "Failed to authenticate")
+				return
+			}
+
+			// Read-only keys can't change anything, even what any participant
+			// could.
+			if service.HasScope(apiKey, service.ScopeReadOnly) && !isSafeMethod(request.Method) {
+				sendServiceError(writer, request, service.ErrMissingScope, "Failed to authorize")
+				return
+			}
+
+			// The key's organization wins over any other one the request names.
+			ctx = context.WithValue(ctx, apiKeyContextKey{}, apiKey)
+			ctx = service.WithOrganization(ctx, apiKey.OrganizationID)
+		} else if reference := readOrganizationReference(request); reference != "" {
+			organization, err := handler.organizations.FindOrganization(ctx, reference)
+			if err != nil {
+				sendServiceError(writer, request, err, "Failed to get organization")
+				return
+			}
+
+			ctx = service.WithOrganization(ctx, organization.ID)
+		}
+
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// requireScope turns away requests authenticated with an API key that wasn't
+// granted the scope. Requests without a key go on to the usual checks.
+func (handler apiHandler) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if apiKey := apiKeyFromContext(request); apiKey != nil && !service.HasScope(*apiKey, scope) {
+				sendServiceError(writer, request, service.ErrMissingScope, "Failed to authorize")
+				return
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// readBearerToken returns the token of an "Authorization: Bearer" header.
+func readBearerToken(request *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(request.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+func readOrganizationReference(request *http.Request) string {
+	if reference := request.Header.Get(organizationHeader); reference != "" {
+		return reference
+	}
+
+	return request.URL.Query().Get("org")
+}
+
+// apiKeyFromContext returns the API key resolveOrganization authenticated the
+// request with, or nil.
+func apiKeyFromContext(request *http.Request) *postgres.ApiKey {
+	apiKey, ok := request.Context().Value(apiKeyContextKey{}).(postgres.ApiKey)
+	if !ok {
+		return nil
+	}
+
+	return &apiKey
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
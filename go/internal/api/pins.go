@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (handler apiHandler) handlePinQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	question, err := handler.questions.PinQuestion(request.Context(), readActor(request), roomID, questionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to pin question")
+		return
+	}
+
+	sendJSON(writer, newQuestionResponse(question))
+
+	go handler.handleNotify(Notification{
+		Category: QuestionPinnedCategory,
+		Value: NotificationValue{
+			ID:    question.ID.String(),
+			Text:  question.Text,
+			Count: int64(question.PinPosition.Int32),
+		},
+		RoomId: rawRoomID,
+	})
+}
+
+func (handler apiHandler) handleUnpinQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, questionID, ok := readQuestionID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	question, err := handler.questions.UnpinQuestion(request.Context(), readActor(request), roomID, questionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to unpin question")
+		return
+	}
+
+	sendJSON(writer, newQuestionResponse(question))
+
+	go handler.handleNotify(Notification{
+		Category: QuestionUnpinnedCategory,
+		Value: NotificationValue{
+			ID:   question.ID.String(),
+			Text: question.Text,
+		},
+		RoomId: rawRoomID,
+	})
+}
+
+func (handler apiHandler) handleReorderPinnedQuestions(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		QuestionIDs []uuid.UUID `json:"question_ids"`
+	}
+
+	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := handler.questions.ReorderPinnedQuestions(request.Context(), readActor(request), roomID, body.QuestionIDs); err != nil {
+		sendServiceError(writer, request, err, "Failed to reorder pinned questions")
+		return
+	}
+
+	type response struct {
+		QuestionIDs []uuid.UUID `json:"question_ids"`
+	}
+
+	sendJSON(writer, response{
+		QuestionIDs: body.QuestionIDs,
+	})
+
+	go handler.handleNotify(Notification{
+		Category: PinsReorderedCategory,
+		Value: NotificationValue{
+			ID:    roomID.String(),
+			Text:  "Pinned questions reordered",
+			Count: int64(len(body.QuestionIDs)),
+		},
+		RoomId: rawRoomID,
+	})
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
+)
+
+func readPollID(
+	writer http.ResponseWriter,
+	request *http.Request,
+) (rawRoomID string, roomID uuid.UUID, pollID uuid.UUID, ok bool) {
+	rawRoomID, roomID, ok = readRoomID(writer, request)
+	if !ok {
+		return "", uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	pollID, err := uuid.Parse(chi.URLParam(request, "poll_id"))
+	if err != nil {
+		http.Error(writer, "Invalid poll ID", http.StatusBadRequest)
+		return "", uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	return rawRoomID, roomID, pollID, true
+}
+
+func (handler apiHandler) handleCreatePoll(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		Question string   `json:"question"`
+		Options  []string `json:"options"`
+	}
+
+	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	poll, err := handler.polls.CreatePoll(request.Context(), readActor(request), roomID, body.Question, body.Options)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to create poll")
+		return
+	}
+
+	handler.sendPoll(writer, rawRoomID, PollOpenedCategory, poll)
+}
+
+func (handler apiHandler) handleGetPolls(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	polls, err := handler.polls.GetRoomPolls(request.Context(), roomID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to get polls")
+		return
+	}
+
+	type response struct {
+		List  []pollResponse `json:"list"`
+		Total int            `json:"total"`
+	}
+
+	list := make([]pollResponse, 0, len(polls))
+	for _, poll := range polls {
+		list = append(list, newPollResponse(poll))
+	}
+
+	sendJSON(writer, response{
+		List:  list,
+		Total: len(polls),
+	})
+}
+
+func (handler apiHandler) handleGetPoll(writer http.ResponseWriter, request *http.Request) {
+	_, roomID, pollID, ok := readPollID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	poll, err := handler.polls.GetPoll(request.Context(), roomID, pollID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to get poll")
+		return
+	}
+
+	sendJSON(writer, newPollResponse(poll))
+}
+
+func (handler apiHandler) handleClosePoll(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, pollID, ok := readPollID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	poll, err := handler.polls.ClosePoll(request.Context(), readActor(request), roomID, pollID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to close poll")
+		return
+	}
+
+	handler.sendPoll(writer, rawRoomID, PollClosedCategory, poll)
+}
+
+func (handler apiHandler) handleReopenPoll(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, pollID, ok := readPollID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	poll, err := handler.polls.ReopenPoll(request.Context(), readActor(request), roomID, pollID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to reopen poll")
+		return
+	}
+
+	handler.sendPoll(writer, rawRoomID, PollOpenedCategory, poll)
+}
+
+func (handler apiHandler) handleVoteInPoll(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, pollID, ok := readPollID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		OptionID string `json:"option_id"`
+	}
+
+	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	optionID, err := uuid.Parse(body.OptionID)
+	if err != nil {
+		http.Error(writer, "Invalid option ID", http.StatusBadRequest)
+		return
+	}
+
+	poll, err := handler.polls.Vote(request.Context(), readActor(request), roomID, pollID, optionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to vote in poll")
+		return
+	}
+
+	handler.sendPoll(writer, rawRoomID, PollResultsCategory, poll)
+}
+
+// sendPoll responds with the poll and broadcasts its results to the room.
+func (handler apiHandler) sendPoll(writer http.ResponseWriter, rawRoomID, category string, poll service.PollDetails) {
+	response := newPollResponse(poll)
+	sendJSON(writer, response)
+
+	go handler.handleNotify(Notification{
+		Category: category,
+		Value: NotificationValue{
+			ID:    response.ID,
+			Text:  response.Question,
+			Count: response.TotalVotes,
+			Poll:  &response,
+		},
+		RoomId: rawRoomID,
+	})
+}
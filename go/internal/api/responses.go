@@ -0,0 +1,190 @@
+package api
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
+)
+
+// The response types below are what the API exposes of the sqlc models. They
+// keep secrets such as token hashes out of responses and render timestamps as
+// RFC 3339 in UTC, with null for the ones that aren't set.
+
+type roomResponse struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Slug       string  `json:"slug"`
+	Visibility string  `json:"visibility"`
+	CreatedAt  string  `json:"created_at"`
+	UpdatedAt  string  `json:"updated_at"`
+	ClosedAt   *string `json:"closed_at"`
+
+	ReactionKinds   []string `json:"reaction_kinds"`
+	PrimaryReaction string   `json:"primary_reaction"`
+	Ranking         string   `json:"ranking"`
+
+	// SpotlightQuestionID is the question the owner is answering now.
+	SpotlightQuestionID *string `json:"spotlight_question_id"`
+	SpotlightedAt       *string `json:"spotlighted_at"`
+
+	// Anonymous rooms hide the names of question authors.
+	Anonymous bool `json:"anonymous"`
+}
+
+func newRoomResponse(room postgres.Room) roomResponse {
+	return roomResponse{
+		ID:         room.ID.String(),
+		Name:       room.Name,
+		Slug:       room.Slug,
+		Visibility: room.Visibility,
+		CreatedAt:  formatTimestamp(room.CreatedAt),
+		UpdatedAt:  formatTimestamp(room.UpdatedAt),
+		ClosedAt:   formatOptionalTimestamp(room.ClosedAt),
+
+		ReactionKinds:   room.ReactionKinds,
+		PrimaryReaction: room.PrimaryReaction,
+		Ranking:         room.Ranking,
+
+		SpotlightQuestionID: formatOptionalUUID(room.SpotlightQuestionID),
+		SpotlightedAt:       formatOptionalTimestamp(room.SpotlightedAt),
+
+		Anonymous: room.Anonymous,
+	}
+}
+
+// questionResponse carries the count of the room's primary reaction kind as
+// reaction_count and the count of every kind in reactions. Pinned questions
+// have a pinned_at. author_name is null for anonymous questions.
+type questionResponse struct {
+	ID            string            `json:"id"`
+	RoomID        string            `json:"room_id"`
+	Text          string            `json:"text"`
+	AuthorName    *string           `json:"author_name"`
+	ReactionCount int64             `json:"reaction_count"`
+	Reactions     service.Reactions `json:"reactions"`
+	DownvoteCount int64             `json:"downvote_count"`
+	CommentCount  int64             `json:"comment_count"`
+	Answered      bool              `json:"answered"`
+	PinnedAt      *string           `json:"pinned_at"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+}
+
+func newQuestionResponse(question service.QuestionDetails) questionResponse {
+	return questionResponse{
+		ID:            question.ID.String(),
+		RoomID:        question.RoomID.String(),
+		Text:          question.Text,
+		AuthorName:    formatOptionalText(question.AuthorName),
+		ReactionCount: question.ReactionCount,
+		Reactions:     question.Reactions,
+		DownvoteCount: question.DownvoteCount,
+		CommentCount:  question.CommentCount,
+		Answered:      question.Answered,
+		PinnedAt:      formatOptionalTimestamp(question.PinnedAt),
+		CreatedAt:     formatTimestamp(question.CreatedAt),
+		UpdatedAt:     formatTimestamp(question.UpdatedAt),
+	}
+}
+
+func newQuestionResponses(questions []service.QuestionDetails) []questionResponse {
+	list := make([]questionResponse, 0, len(questions))
+	for _, question := range questions {
+		list = append(list, newQuestionResponse(question))
+	}
+
+	return list
+}
+
+type commentResponse struct {
+	ID         string `json:"id"`
+	QuestionID string `json:"question_id"`
+	Text       string `json:"text"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func newCommentResponse(comment postgres.Comment) commentResponse {
+	return commentResponse{
+		ID:         comment.ID.String(),
+		QuestionID: comment.QuestionID.String(),
+		Text:       comment.Text,
+		CreatedAt:  formatTimestamp(comment.CreatedAt),
+	}
+}
+
+type pollOptionResponse struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	VoteCount int64  `json:"vote_count"`
+}
+
+type pollResponse struct {
+	ID         string               `json:"id"`
+	RoomID     string               `json:"room_id"`
+	Question   string               `json:"question"`
+	Status     string               `json:"status"`
+	Options    []pollOptionResponse `json:"options"`
+	TotalVotes int64                `json:"total_votes"`
+	CreatedAt  string               `json:"created_at"`
+	ClosedAt   *string              `json:"closed_at"`
+}
+
+func newPollResponse(poll service.PollDetails) pollResponse {
+	response := pollResponse{
+		ID:        poll.ID.String(),
+		RoomID:    poll.RoomID.String(),
+		Question:  poll.Question,
+		Status:    "open",
+		Options:   make([]pollOptionResponse, 0, len(poll.Options)),
+		CreatedAt: formatTimestamp(poll.CreatedAt),
+		ClosedAt:  formatOptionalTimestamp(poll.ClosedAt),
+	}
+
+	if poll.ClosedAt.Valid {
+		response.Status = "closed"
+	}
+
+	for _, option := range poll.Options {
+		response.Options = append(response.Options, pollOptionResponse{
+			ID:        option.ID.String(),
+			Text:      option.Text,
+			VoteCount: option.VoteCount,
+		})
+		response.TotalVotes += option.VoteCount
+	}
+
+	return response
+}
+
+func formatTimestamp(timestamp pgtype.Timestamptz) string {
+	return timestamp.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func formatOptionalText(text pgtype.Text) *string {
+	if !text.Valid {
+		return nil
+	}
+
+	return &text.String
+}
+
+func formatOptionalUUID(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+
+	formatted := uuid.UUID(id.Bytes).String()
+	return &formatted
+}
+
+func formatOptionalTimestamp(timestamp pgtype.Timestamptz) *string {
+	if !timestamp.Valid {
+		return nil
+	}
+
+	formatted := formatTimestamp(timestamp)
+	return &formatted
+}
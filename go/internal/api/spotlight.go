@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (handler apiHandler) handleSpotlightQuestion(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	type _body struct {
+		QuestionID string `json:"question_id"`
+	}
+
+	var body _body
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	questionID, err := uuid.Parse(body.QuestionID)
+	if err != nil {
+		http.Error(writer, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+
+	room, question, err := handler.rooms.SpotlightQuestion(request.Context(), readActor(request), roomID, questionID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to spotlight question")
+		return
+	}
+
+	sendJSON(writer, newRoomResponse(room))
+
+	go handler.handleNotify(Notification{
+		Category: QuestionSpotlightedCategory,
+		Value: NotificationValue{
+			ID:   question.ID.String(),
+			Text: question.Text,
+		},
+		RoomId: rawRoomID,
+	})
+}
+
+func (handler apiHandler) handleClearSpotlight(writer http.ResponseWriter, request *http.Request) {
+	rawRoomID, roomID, ok := readRoomID(writer, request)
+
+	if !ok {
+		return
+	}
+
+	room, err := handler.rooms.ClearSpotlight(request.Context(), readActor(request), roomID)
+	if err != nil {
+		sendServiceError(writer, request, err, "Failed to clear spotlight")
+		return
+	}
+
+	sendJSON(writer, newRoomResponse(room))
+
+	go handler.handleNotify(Notification{
+		Category: SpotlightClearedCategory,
+		Value: NotificationValue{
+			ID:   room.ID.String(),
+			Text: "Spotlight cleared",
+		},
+		RoomId: rawRoomID,
+	})
+}
@@ -3,43 +3,165 @@ package api
 import (
 	"encoding/json"
 	"errors"
-	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
 	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/service"
 )
 
+// readRoom resolves the room of the request from its ID or its slug, making
+// sure the actor is allowed in. The raw room ID it returns is the canonical
+// form of the ID, which keys the subscribers whichever way the URL named the
+// room.
 func (handler apiHandler) readRoom(
 	writer http.ResponseWriter,
 	request *http.Request,
 ) (room postgres.Room, rawRoomID string, roomID uuid.UUID, ok bool) {
-	rawRoomID = chi.URLParam(request, "room_id")
-	roomID, err := uuid.Parse(rawRoomID)
+	room, err := handler.rooms.EnterRoom(request.Context(), readActor(request), chi.URLParam(request, "room_id"))
 	if err != nil {
-		http.Error(writer, "Invalid room ID", http.StatusBadRequest)
+		sendServiceError(writer, request, err, "Failed to get room")
 		return postgres.Room{}, "", uuid.UUID{}, false
 	}
 
-	room, err = handler.query.GetRoom(request.Context(), roomID)
+	return room, room.ID.String(), room.ID, true
+}
+
+type roomContextKey struct{}
+
+// roomFromContext returns the room that requireRoomAccess resolved for the
+// request, or the zero room outside of its routes.
+func roomFromContext(request *http.Request) postgres.Room {
+	room, _ := request.Context().Value(roomContextKey{}).(postgres.Room)
+	return room
+}
+
+// readRoomID returns the room that requireRoomAccess resolved for the request.
+func readRoomID(
+	writer http.ResponseWriter,
+	request *http.Request,
+) (rawRoomID string, roomID uuid.UUID, ok bool) {
+	room, ok := request.Context().Value(roomContextKey{}).(postgres.Room)
+	if !ok {
+		http.Error(writer, "Room not found", http.StatusNotFound)
+		return "", uuid.UUID{}, false
+	}
+
+	return room.ID.String(), room.ID, true
+}
+
+const (
+	// ownerTokenHeader carries the token returned when a room is created.
+	ownerTokenHeader = "X-Owner-Token"
+	// participantIDHeader carries the identifier a client generates for
+	// itself, which makes it the author of the questions it asks.
+	participantIDHeader = "X-Participant-ID"
+	// joinCodeHeader carries the join code of a private room, or of any room
+	// outside the default organization. Browsers can't set headers on
+	// websockets, so the code query parameter works as well.
+	joinCodeHeader = "X-Join-Code"
+	// organizationHeader names the organization, by slug or ID, whose rooms
+	// the request is about. The org query parameter works as well.
+	organizationHeader = "X-Organization"
+)
+
+func readActor(request *http.Request) service.Actor {
+	joinCode := request.Header.Get(joinCodeHeader)
+	if joinCode == "" {
+		joinCode = request.URL.Query().Get("code")
+	}
+
+	return service.Actor{
+		OwnerToken:    request.Header.Get(ownerTokenHeader),
+		ParticipantID: request.Header.Get(participantIDHeader),
+		JoinCode:      joinCode,
+		APIKey:        apiKeyFromContext(request),
+	}
+}
+
+func readQuestionID(
+	writer http.ResponseWriter,
+	request *http.Request,
+) (rawRoomID string, roomID uuid.UUID, questionID uuid.UUID, ok bool) {
+	rawRoomID, roomID, ok = readRoomID(writer, request)
+	if !ok {
+		return "", uuid.UUID{}, uuid.UUID{}, false
+	}
+
+	questionID, err := uuid.Parse(chi.URLParam(request, "question_id"))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			http.Error(writer, "Room not found", http.StatusBadRequest)
-			return postgres.Room{}, "", uuid.UUID{}, false
-		}
+		http.Error(writer, "Invalid question ID", http.StatusBadRequest)
+		return "", uuid.UUID{}, uuid.UUID{}, false
+	}
 
-		slog.Error("Failed to get room", "error", err)
-		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
-		return postgres.Room{}, "", uuid.UUID{}, false
+	return rawRoomID, roomID, questionID, true
+}
+
+// readIntQuery parses an optional integer query parameter, which is zero when
+// it's missing.
+func readIntQuery(writer http.ResponseWriter, request *http.Request, name string) (int, bool) {
+	raw := request.URL.Query().Get(name)
+	if raw == "" {
+		return 0, true
 	}
 
-	return room, rawRoomID, roomID, true
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		http.Error(writer, "Invalid "+name, http.StatusBadRequest)
+		return 0, false
+	}
+
+	return value, true
+}
+
+// sendServiceError maps the domain errors returned by the service layer to
+// status codes, logging anything unexpected with the request's logger.
+func sendServiceError(writer http.ResponseWriter, request *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrRoomNotFound):
+		http.Error(writer, "Room not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		http.Error(writer, "Organization not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidAPIKey):
+		writer.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(writer, "Invalid API key", http.StatusUnauthorized)
+	case errors.Is(err, service.ErrMissingScope):
+		http.Error(writer, "API key is missing the required scope", http.StatusForbidden)
+	case errors.Is(err, service.ErrRoomClosed):
+		http.Error(writer, "Room is closed", http.StatusConflict)
+	case errors.Is(err, service.ErrQuestionNotFound):
+		http.Error(writer, "Question not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrCommentNotFound):
+		http.Error(writer, "Comment not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrPollNotFound):
+		http.Error(writer, "Poll not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrPollClosed):
+		http.Error(writer, "Poll is closed", http.StatusConflict)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(writer, "You are not allowed to do this", http.StatusForbidden)
+	case errors.Is(err, service.ErrJoinCodeRequired):
+		http.Error(writer, "Room requires a join code", http.StatusForbidden)
+	case errors.Is(err, service.ErrSlugTaken):
+		http.Error(writer, "Slug is already taken", http.StatusConflict)
+	case errors.Is(err, service.ErrQuestionLocked):
+		http.Error(writer, "Question can no longer be edited", http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidInput):
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+	default:
+		loggerFromContext(request.Context()).Error(message, "error", err)
+		http.Error(writer, "Something went wrong", http.StatusInternalServerError)
+	}
 }
 
 func sendJSON(writer http.ResponseWriter, rawData any) {
+	sendJSONWithStatus(writer, http.StatusOK, rawData)
+}
+
+func sendJSONWithStatus(writer http.ResponseWriter, status int, rawData any) {
 	data, _ := json.Marshal(rawData)
 	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
 	_, _ = writer.Write(data)
 }
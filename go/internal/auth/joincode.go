@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"math/big"
+	"strings"
+)
+
+// joinCodeAlphabet leaves out the characters that are easily confused when
+// read out loud or copied by hand: 0 and O, 1, I and L.
+const joinCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const joinCodeLength = 8
+
+// NewJoinCode returns a short code such as "K7QM-3XHP" that is meant to be
+// shared with people, unlike the tokens.
+func NewJoinCode() (string, error) {
+	var code strings.Builder
+	max := big.NewInt(int64(len(joinCodeAlphabet)))
+
+	for index := 0; index < joinCodeLength; index++ {
+		if index == joinCodeLength/2 {
+			code.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(joinCodeAlphabet[n.Int64()])
+	}
+
+	return code.String(), nil
+}
+
+// MatchJoinCode reports whether input is the join code, ignoring case,
+// spaces and dashes. An empty code never matches.
+func MatchJoinCode(input, code string) bool {
+	input, code = normalizeJoinCode(input), normalizeJoinCode(code)
+	if input == "" || code == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(input), []byte(code)) == 1
+}
+
+func normalizeJoinCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(code))
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJoinCodes(t *testing.T) {
+	code, err := NewJoinCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(code) != joinCodeLength+1 || code[joinCodeLength/2] != '-' {
+		t.Fatalf("unexpected join code format %q", code)
+	}
+	if strings.ContainsAny(code, "01IOL") {
+		t.Fatalf("expected no ambiguous characters in %q", code)
+	}
+
+	relaxed := strings.ToLower(strings.ReplaceAll(code, "-", " "))
+	if !MatchJoinCode(relaxed, code) {
+		t.Fatalf("expected %q to match %q", relaxed, code)
+	}
+	if MatchJoinCode("AAAA-AAAA", code) && code != "AAAA-AAAA" {
+		t.Fatal("expected another code not to match")
+	}
+	if MatchJoinCode("", code) || MatchJoinCode(code, "") {
+		t.Fatal("expected empty values never to match")
+	}
+}
@@ -0,0 +1,36 @@
+// Package auth generates and verifies the secret tokens handed out by the API.
+// Only SHA-256 hashes of the tokens are stored, so a leaked database doesn't
+// leak working credentials.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// NewToken returns a random, URL safe token with 256 bits of entropy.
+func NewToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func HashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// MatchToken reports whether token hashes to hash, in constant time. An empty
+// token or hash never matches.
+func MatchToken(token string, hash []byte) bool {
+	if token == "" || len(hash) == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(HashToken(token), hash) == 1
+}
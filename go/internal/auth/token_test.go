@@ -0,0 +1,30 @@
+package auth
+
+import "testing"
+
+func TestTokens(t *testing.T) {
+	token, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token == other {
+		t.Fatal("expected two different tokens")
+	}
+
+	hash := HashToken(token)
+	if !MatchToken(token, hash) {
+		t.Fatal("expected the token to match its hash")
+	}
+	if MatchToken(other, hash) {
+		t.Fatal("expected another token not to match")
+	}
+	if MatchToken("", hash) || MatchToken(token, nil) {
+		t.Fatal("expected empty values never to match")
+	}
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// unaudited lists the columns the audit_change trigger leaves out.
+var unaudited = map[string]bool{
+	"updated_at":        true,
+	"reaction_count":    true,
+	"downvote_count":    true,
+	"spotlight_seconds": true,
+	"owner_token_hash":  true,
+	"author_hash":       true,
+}
+
+// SetAuditActor only lasts for the transaction, like set_config with is_local.
+func (store *Store) SetAuditActor(ctx context.Context, actor string) error {
+	if store.inTx {
+		store.actor = actor
+	}
+
+	return nil
+}
+
+func (store *Store) GetRoomAuditLog(ctx context.Context, roomID uuid.UUID) ([]postgres.AuditLog, error) {
+	defer store.lock()()
+
+	var entries []postgres.AuditLog
+	for _, entry := range store.data.auditLog {
+		if entry.RoomID == roomID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// updateRoom applies change to the room the way an UPDATE does, firing the
+// updated_at and audit triggers when anything actually changed.
+func (store *Store) updateRoom(room *postgres.Room, change func(room *postgres.Room)) {
+	before := *room
+	change(room)
+
+	if reflect.DeepEqual(before, *room) {
+		return
+	}
+
+	room.UpdatedAt = now()
+	store.audit("room", room.ID, room.ID, "update", roomColumns(&before), roomColumns(room))
+}
+
+// updateQuestion is updateRoom for questions.
+func (store *Store) updateQuestion(question *postgres.Question, change func(question *postgres.Question)) {
+	before := *question
+	change(question)
+
+	if reflect.DeepEqual(before, *question) {
+		return
+	}
+
+	question.UpdatedAt = now()
+	store.audit("question", question.RoomID, question.ID, "update", questionColumns(&before), questionColumns(question))
+}
+
+// audit mirrors the audit_change trigger. before is nil for inserts and after
+// is nil for deletes.
+func (store *Store) audit(entity string, roomID, entityID uuid.UUID, action string, before, after map[string]any) {
+	type change struct {
+		Old any `json:"old"`
+		New any `json:"new"`
+	}
+
+	columns := after
+	if columns == nil {
+		columns = before
+	}
+
+	changes := make(map[string]change)
+	for key := range columns {
+		if unaudited[key] {
+			continue
+		}
+
+		if before != nil && after != nil && reflect.DeepEqual(before[key], after[key]) {
+			continue
+		}
+
+		changes[key] = change{Old: before[key], New: after[key]}
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	actor := store.actor
+	if actor == "" {
+		actor = "system"
+	}
+
+	encoded, _ := json.Marshal(changes)
+
+	store.data.nextAuditID++
+	store.data.auditLog = append(store.data.auditLog, postgres.AuditLog{
+		ID:        store.data.nextAuditID,
+		RoomID:    roomID,
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Actor:     actor,
+		Changes:   encoded,
+		CreatedAt: now(),
+	})
+}
+
+// roomColumns and questionColumns encode a row the way to_jsonb does.
+func roomColumns(room *postgres.Room) map[string]any {
+	return map[string]any{
+		"id":                    room.ID.String(),
+		"name":                  room.Name,
+		"created_at":            timestampColumn(room.CreatedAt),
+		"updated_at":            timestampColumn(room.UpdatedAt),
+		"closed_at":             timestampColumn(room.ClosedAt),
+		"deleted_at":            timestampColumn(room.DeletedAt),
+		"visibility":            room.Visibility,
+		"join_code":             textColumn(room.JoinCode),
+		"slug":                  room.Slug,
+		"reaction_kinds":        room.ReactionKinds,
+		"primary_reaction":      room.PrimaryReaction,
+		"ranking":               room.Ranking,
+		"spotlight_question_id": uuidColumn(room.SpotlightQuestionID),
+		"spotlighted_at":        timestampColumn(room.SpotlightedAt),
+		"anonymous":             room.Anonymous,
+		"organization_id":       room.OrganizationID.String(),
+	}
+}
+
+func questionColumns(question *postgres.Question) map[string]any {
+	return map[string]any{
+		"id":                question.ID.String(),
+		"room_id":           question.RoomID.String(),
+		"text":              question.Text,
+		"reaction_count":    question.ReactionCount,
+		"downvote_count":    question.DownvoteCount,
+		"spotlight_seconds": question.SpotlightSeconds,
+		"pinned_at":         timestampColumn(question.PinnedAt),
+		"pin_position":      int4Column(question.PinPosition),
+		"author_name":       textColumn(question.AuthorName),
+		"answered":          question.Answered,
+		"created_at":        timestampColumn(question.CreatedAt),
+		"updated_at":        timestampColumn(question.UpdatedAt),
+		"deleted_at":        timestampColumn(question.DeletedAt),
+	}
+}
+
+func textColumn(text pgtype.Text) any {
+	if !text.Valid {
+		return nil
+	}
+
+	return text.String
+}
+
+func int4Column(value pgtype.Int4) any {
+	if !value.Valid {
+		return nil
+	}
+
+	return value.Int32
+}
+
+func uuidColumn(id pgtype.UUID) any {
+	if !id.Valid {
+		return nil
+	}
+
+	return uuid.UUID(id.Bytes).String()
+}
+
+func timestampColumn(timestamp pgtype.Timestamptz) any {
+	if !timestamp.Valid {
+		return nil
+	}
+
+	return timestamp.Time.Format("2006-01-02T15:04:05.999999-07:00")
+}
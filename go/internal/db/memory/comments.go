@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func (store *Store) CreateComment(ctx context.Context, arg postgres.CreateCommentParams) (postgres.Comment, error) {
+	defer store.lock()()
+
+	if store.data.question(arg.QuestionID) == nil {
+		return postgres.Comment{}, foreignKeyViolation("comment_question_id_fkey")
+	}
+
+	comment := postgres.Comment{
+		ID:         uuid.New(),
+		QuestionID: arg.QuestionID,
+		Text:       arg.Text,
+		AuthorHash: arg.AuthorHash,
+		CreatedAt:  now(),
+	}
+	store.data.comments = append(store.data.comments, comment)
+
+	return comment, nil
+}
+
+func (store *Store) GetComment(ctx context.Context, id uuid.UUID) (postgres.Comment, error) {
+	defer store.lock()()
+
+	for _, comment := range store.data.comments {
+		if comment.ID == id {
+			return comment, nil
+		}
+	}
+
+	return postgres.Comment{}, pgx.ErrNoRows
+}
+
+func (store *Store) GetQuestionComments(ctx context.Context, questionID uuid.UUID) ([]postgres.Comment, error) {
+	defer store.lock()()
+
+	var comments []postgres.Comment
+	for _, comment := range store.data.comments {
+		if comment.QuestionID == questionID {
+			comments = append(comments, comment)
+		}
+	}
+
+	return comments, nil
+}
+
+func (store *Store) DeleteComment(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	before := len(store.data.comments)
+	store.data.comments = slices.DeleteFunc(store.data.comments, func(comment postgres.Comment) bool {
+		return comment.ID == id
+	})
+
+	return int64(before - len(store.data.comments)), nil
+}
+
+func (store *Store) CountQuestionComments(ctx context.Context, questionID uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	var count int64
+	for _, comment := range store.data.comments {
+		if comment.QuestionID == questionID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (store *Store) GetRoomCommentCounts(ctx context.Context, roomID uuid.UUID) ([]postgres.GetRoomCommentCountsRow, error) {
+	defer store.lock()()
+
+	var rows []postgres.GetRoomCommentCountsRow
+	indexes := make(map[uuid.UUID]int)
+	for _, comment := range store.data.comments {
+		question := store.data.activeQuestion(comment.QuestionID)
+		if question == nil || question.RoomID != roomID {
+			continue
+		}
+
+		index, ok := indexes[comment.QuestionID]
+		if !ok {
+			index = len(rows)
+			indexes[comment.QuestionID] = index
+			rows = append(rows, postgres.GetRoomCommentCountsRow{QuestionID: comment.QuestionID})
+		}
+		rows[index].CommentCount++
+	}
+
+	return rows, nil
+}
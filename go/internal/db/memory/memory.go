@@ -0,0 +1,133 @@
+// Package memory implements db.Store on top of plain Go data structures so the
+// API can be exercised without a database. It mirrors the behaviour of the
+// queries in queries.sql closely enough for tests, including pgx.ErrNoRows for
+// missing rows, foreign key violations for unknown rooms and organizations and
+// unique violations for taken slugs.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+var _ db.Store = (*Store)(nil)
+
+// Store is safe for concurrent use. Transactions are serialized and a failed
+// transaction restores the state it started from.
+type Store struct {
+	mutex *sync.Mutex
+	data  *dataset
+	inTx  bool
+	// actor is what SetAuditActor set for the running transaction.
+	actor string
+}
+
+type dataset struct {
+	organizations []postgres.Organization
+	apiKeys       []postgres.ApiKey
+
+	rooms     []postgres.Room
+	grants    []postgres.RoomGrant
+	questions []postgres.Question
+	edits     []postgres.QuestionEdit
+	reactions []postgres.QuestionReaction
+	comments  []postgres.Comment
+
+	polls       []postgres.Poll
+	pollOptions []postgres.PollOption
+	pollVotes   []postgres.PollVote
+
+	auditLog    []postgres.AuditLog
+	nextAuditID int64
+}
+
+// New returns an empty store holding just the default organization, like a
+// freshly migrated database.
+func New() *Store {
+	return &Store{mutex: &sync.Mutex{}, data: &dataset{
+		organizations: []postgres.Organization{{
+			ID:        db.DefaultOrganizationID,
+			Name:      "Default",
+			Slug:      "default",
+			CreatedAt: now(),
+		}},
+	}}
+}
+
+func (store *Store) ExecTx(ctx context.Context, fn func(query postgres.Querier) error) error {
+	if store.inTx {
+		return fn(store)
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	snapshot := store.data.clone()
+
+	if err := fn(&Store{mutex: store.mutex, data: store.data, inTx: true}); err != nil {
+		*store.data = *snapshot
+		return err
+	}
+
+	return nil
+}
+
+// lock acquires the store for a single query unless it already runs inside a
+// transaction, which holds the lock for its whole duration.
+func (store *Store) lock() func() {
+	if store.inTx {
+		return func() {}
+	}
+
+	store.mutex.Lock()
+	return store.mutex.Unlock
+}
+
+func (data *dataset) clone() *dataset {
+	return &dataset{
+		organizations: append([]postgres.Organization(nil), data.organizations...),
+		apiKeys:       append([]postgres.ApiKey(nil), data.apiKeys...),
+
+		rooms:     append([]postgres.Room(nil), data.rooms...),
+		grants:    append([]postgres.RoomGrant(nil), data.grants...),
+		questions: append([]postgres.Question(nil), data.questions...),
+		edits:     append([]postgres.QuestionEdit(nil), data.edits...),
+		reactions: append([]postgres.QuestionReaction(nil), data.reactions...),
+		comments:  append([]postgres.Comment(nil), data.comments...),
+
+		polls:       append([]postgres.Poll(nil), data.polls...),
+		pollOptions: append([]postgres.PollOption(nil), data.pollOptions...),
+		pollVotes:   append([]postgres.PollVote(nil), data.pollVotes...),
+
+		auditLog:    append([]postgres.AuditLog(nil), data.auditLog...),
+		nextAuditID: data.nextAuditID,
+	}
+}
+
+func now() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now().UTC(), Valid: true}
+}
+
+func foreignKeyViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        "insert or update violates foreign key constraint",
+		ConstraintName: constraint,
+	}
+}
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        "duplicate key value violates unique constraint",
+		ConstraintName: constraint,
+	}
+}
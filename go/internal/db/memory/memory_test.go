@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func TestExecTxRollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	failure := errors.New("boom")
+	err := store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := query.CreateRoom(ctx, postgres.CreateRoomParams{Name: "discarded", Visibility: "public", Slug: "discarded", OrganizationID: db.DefaultOrganizationID}); err != nil {
+			return err
+		}
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+
+	rooms, _ := store.GetRooms(ctx, db.DefaultOrganizationID)
+	if len(rooms) != 0 {
+		t.Fatalf("expected the room to be rolled back, got %d rooms", len(rooms))
+	}
+}
+
+func TestMissingRows(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	if _, err := store.GetRoom(ctx, postgres.GetRoomParams{ID: uuid.New(), OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+	}
+
+	if _, err := store.CreateQuestion(ctx, postgres.CreateQuestionParams{RoomID: uuid.New(), Text: "orphan"}); err == nil {
+		t.Fatal("expected a foreign key violation for an unknown room")
+	}
+}
+
+func TestHardDeletesIncludeSoftDeletedRows(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	room, err := store.CreateRoom(ctx, postgres.CreateRoomParams{Name: "Go AMA", Visibility: "public", Slug: "go-ama", OrganizationID: db.DefaultOrganizationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	question, err := store.CreateQuestion(ctx, postgres.CreateQuestionParams{RoomID: room.ID, Text: "Generics?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.SoftDeleteQuestion(ctx, question.ID); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := store.DeleteRoomQuestions(ctx, room.ID); err != nil || deleted != 1 {
+		t.Fatalf("expected the soft-deleted question to be purged, got %d, %v", deleted, err)
+	}
+
+	if _, err := store.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := store.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil || deleted != 1 {
+		t.Fatalf("expected the soft-deleted room to be deleted, got %d, %v", deleted, err)
+	}
+}
+
+func TestRoomsWithQuestionCountSkipDeletedRooms(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	for _, slug := range []string{"kept", "deleted"} {
+		if _, err := store.CreateRoom(ctx, postgres.CreateRoomParams{Name: slug, Visibility: "public", Slug: slug, OrganizationID: db.DefaultOrganizationID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deleted, err := store.GetRoomBySlug(ctx, postgres.GetRoomBySlugParams{Slug: "deleted", OrganizationID: db.DefaultOrganizationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: deleted.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+
+	rooms, err := store.GetRoomsWithQuestionCount(ctx, postgres.GetRoomsWithQuestionCountParams{OrganizationID: db.DefaultOrganizationID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rooms) != 1 || rooms[0].Slug != "kept" {
+		t.Fatalf("expected only the kept room, got %+v", rooms)
+	}
+
+	rooms, err = store.GetRoomsWithQuestionCount(ctx, postgres.GetRoomsWithQuestionCountParams{OrganizationID: db.DefaultOrganizationID, IncludeDeleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rooms) != 2 {
+		t.Fatalf("expected the deleted room on request, got %+v", rooms)
+	}
+}
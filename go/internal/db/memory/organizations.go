@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func (store *Store) CreateOrganization(ctx context.Context, arg postgres.CreateOrganizationParams) (postgres.Organization, error) {
+	defer store.lock()()
+
+	for _, organization := range store.data.organizations {
+		if organization.Slug == arg.Slug {
+			return postgres.Organization{}, uniqueViolation("organization_slug_key")
+		}
+	}
+
+	organization := postgres.Organization{
+		ID:        uuid.New(),
+		Name:      arg.Name,
+		Slug:      arg.Slug,
+		CreatedAt: now(),
+	}
+	store.data.organizations = append(store.data.organizations, organization)
+
+	return organization, nil
+}
+
+func (store *Store) GetOrganization(ctx context.Context, id uuid.UUID) (postgres.Organization, error) {
+	defer store.lock()()
+
+	organization := store.data.organization(id)
+	if organization == nil {
+		return postgres.Organization{}, pgx.ErrNoRows
+	}
+
+	return *organization, nil
+}
+
+func (store *Store) GetOrganizationBySlug(ctx context.Context, slug string) (postgres.Organization, error) {
+	defer store.lock()()
+
+	for _, organization := range store.data.organizations {
+		if organization.Slug == slug {
+			return organization, nil
+		}
+	}
+
+	return postgres.Organization{}, pgx.ErrNoRows
+}
+
+func (store *Store) GetOrganizations(ctx context.Context) ([]postgres.Organization, error) {
+	defer store.lock()()
+
+	return append([]postgres.Organization(nil), store.data.organizations...), nil
+}
+
+func (store *Store) CreateAPIKey(ctx context.Context, arg postgres.CreateAPIKeyParams) (postgres.ApiKey, error) {
+	defer store.lock()()
+
+	if store.data.organization(arg.OrganizationID) == nil {
+		return postgres.ApiKey{}, foreignKeyViolation("api_key_organization_id_fkey")
+	}
+
+	key := postgres.ApiKey{
+		ID:             uuid.New(),
+		OrganizationID: arg.OrganizationID,
+		Name:           arg.Name,
+		KeyHash:        arg.KeyHash,
+		CreatedAt:      now(),
+		Scopes:         append([]string(nil), arg.Scopes...),
+	}
+	store.data.apiKeys = append(store.data.apiKeys, key)
+
+	return key, nil
+}
+
+func (store *Store) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (postgres.ApiKey, error) {
+	defer store.lock()()
+
+	for _, key := range store.data.apiKeys {
+		if bytes.Equal(key.KeyHash, keyHash) && !key.RevokedAt.Valid {
+			return key, nil
+		}
+	}
+
+	return postgres.ApiKey{}, pgx.ErrNoRows
+}
+
+func (store *Store) GetOrganizationAPIKeys(ctx context.Context, organizationID uuid.UUID) ([]postgres.ApiKey, error) {
+	defer store.lock()()
+
+	var keys []postgres.ApiKey
+	for _, key := range store.data.apiKeys {
+		if key.OrganizationID == organizationID {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (store *Store) RevokeAPIKey(ctx context.Context, arg postgres.RevokeAPIKeyParams) (postgres.ApiKey, error) {
+	defer store.lock()()
+
+	key := store.data.apiKey(arg.ID)
+	if key == nil || key.OrganizationID != arg.OrganizationID {
+		return postgres.ApiKey{}, pgx.ErrNoRows
+	}
+
+	if !key.RevokedAt.Valid {
+		key.RevokedAt = now()
+	}
+
+	return *key, nil
+}
+
+func (store *Store) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	defer store.lock()()
+
+	key := store.data.apiKey(id)
+	if key == nil {
+		return nil
+	}
+
+	if !key.LastUsedAt.Valid || time.Since(key.LastUsedAt.Time) > time.Minute {
+		key.LastUsedAt = now()
+	}
+
+	return nil
+}
+
+func (data *dataset) organization(id uuid.UUID) *postgres.Organization {
+	for index := range data.organizations {
+		if data.organizations[index].ID == id {
+			return &data.organizations[index]
+		}
+	}
+
+	return nil
+}
+
+func (data *dataset) apiKey(id uuid.UUID) *postgres.ApiKey {
+	for index := range data.apiKeys {
+		if data.apiKeys[index].ID == id {
+			return &data.apiKeys[index]
+		}
+	}
+
+	return nil
+}
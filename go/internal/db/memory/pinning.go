@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// PinQuestion leaves a pinned question where it is and puts a newly pinned one
+// after the room's other pins.
+func (store *Store) PinQuestion(ctx context.Context, id uuid.UUID) (postgres.Question, error) {
+	defer store.lock()()
+
+	question := store.data.question(id)
+	if question == nil {
+		return postgres.Question{}, pgx.ErrNoRows
+	}
+
+	var last int32
+	for _, other := range store.data.questions {
+		if other.RoomID == question.RoomID && other.PinPosition.Valid {
+			last = max(last, other.PinPosition.Int32)
+		}
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		if !question.PinnedAt.Valid {
+			question.PinnedAt = now()
+		}
+		if !question.PinPosition.Valid {
+			question.PinPosition = pgtype.Int4{Int32: last + 1, Valid: true}
+		}
+	})
+
+	return *question, nil
+}
+
+func (store *Store) UnpinQuestion(ctx context.Context, id uuid.UUID) (postgres.Question, error) {
+	defer store.lock()()
+
+	question := store.data.question(id)
+	if question == nil {
+		return postgres.Question{}, pgx.ErrNoRows
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.PinnedAt = pgtype.Timestamptz{}
+		question.PinPosition = pgtype.Int4{}
+	})
+
+	return *question, nil
+}
+
+func (store *Store) GetRoomPinnedQuestionIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	defer store.lock()()
+
+	var pinned []postgres.Question
+	for _, question := range store.data.questions {
+		if question.RoomID == roomID && question.PinnedAt.Valid && !question.DeletedAt.Valid {
+			pinned = append(pinned, question)
+		}
+	}
+
+	slices.SortStableFunc(pinned, comparePins)
+
+	ids := make([]uuid.UUID, 0, len(pinned))
+	for _, question := range pinned {
+		ids = append(ids, question.ID)
+	}
+
+	return ids, nil
+}
+
+// ReorderPinnedQuestions numbers the pinned questions of the room from 1 in the
+// order of the IDs, like unnest WITH ORDINALITY does.
+func (store *Store) ReorderPinnedQuestions(ctx context.Context, arg postgres.ReorderPinnedQuestionsParams) error {
+	defer store.lock()()
+
+	for index, id := range arg.QuestionIds {
+		question := store.data.question(id)
+		if question == nil || question.RoomID != arg.RoomID || !question.PinnedAt.Valid {
+			continue
+		}
+
+		store.updateQuestion(question, func(question *postgres.Question) {
+			question.PinPosition = pgtype.Int4{Int32: int32(index + 1), Valid: true}
+		})
+	}
+
+	return nil
+}
+
+// comparePins orders pinned questions by position before the unpinned ones,
+// like ORDER BY "pin_position" NULLS LAST.
+func comparePins(a, b postgres.Question) int {
+	switch {
+	case a.PinPosition.Valid && b.PinPosition.Valid:
+		return cmp.Compare(a.PinPosition.Int32, b.PinPosition.Int32)
+	case a.PinPosition.Valid:
+		return -1
+	case b.PinPosition.Valid:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func (store *Store) CreatePoll(ctx context.Context, arg postgres.CreatePollParams) (postgres.Poll, error) {
+	defer store.lock()()
+
+	if store.data.room(arg.RoomID) == nil {
+		return postgres.Poll{}, foreignKeyViolation("poll_room_id_fkey")
+	}
+
+	poll := postgres.Poll{
+		ID:        uuid.New(),
+		RoomID:    arg.RoomID,
+		Question:  arg.Question,
+		CreatedAt: now(),
+	}
+	store.data.polls = append(store.data.polls, poll)
+
+	return poll, nil
+}
+
+// CreatePollOptions numbers the options from 1 in the order of the texts, like
+// unnest WITH ORDINALITY does.
+func (store *Store) CreatePollOptions(ctx context.Context, arg postgres.CreatePollOptionsParams) error {
+	defer store.lock()()
+
+	if store.data.poll(arg.PollID) == nil {
+		return foreignKeyViolation("poll_option_poll_id_fkey")
+	}
+
+	for index, text := range arg.Texts {
+		store.data.pollOptions = append(store.data.pollOptions, postgres.PollOption{
+			ID:       uuid.New(),
+			PollID:   arg.PollID,
+			Position: int32(index + 1),
+			Text:     text,
+		})
+	}
+
+	return nil
+}
+
+func (store *Store) GetPoll(ctx context.Context, id uuid.UUID) (postgres.Poll, error) {
+	defer store.lock()()
+
+	poll := store.data.poll(id)
+	if poll == nil {
+		return postgres.Poll{}, pgx.ErrNoRows
+	}
+
+	return *poll, nil
+}
+
+func (store *Store) GetRoomPolls(ctx context.Context, roomID uuid.UUID) ([]postgres.Poll, error) {
+	defer store.lock()()
+
+	var polls []postgres.Poll
+	for _, poll := range store.data.polls {
+		if poll.RoomID == roomID {
+			polls = append(polls, poll)
+		}
+	}
+
+	return polls, nil
+}
+
+func (store *Store) ClosePoll(ctx context.Context, id uuid.UUID) (postgres.Poll, error) {
+	defer store.lock()()
+
+	poll := store.data.poll(id)
+	if poll == nil {
+		return postgres.Poll{}, pgx.ErrNoRows
+	}
+
+	if !poll.ClosedAt.Valid {
+		poll.ClosedAt = now()
+	}
+
+	return *poll, nil
+}
+
+func (store *Store) ReopenPoll(ctx context.Context, id uuid.UUID) (postgres.Poll, error) {
+	defer store.lock()()
+
+	poll := store.data.poll(id)
+	if poll == nil {
+		return postgres.Poll{}, pgx.ErrNoRows
+	}
+
+	poll.ClosedAt.Valid = false
+	return *poll, nil
+}
+
+// VoteInPoll moves the participant's vote when they already voted, like the
+// ON CONFLICT clause in queries.sql.
+func (store *Store) VoteInPoll(ctx context.Context, arg postgres.VoteInPollParams) error {
+	defer store.lock()()
+
+	if store.data.poll(arg.PollID) == nil {
+		return foreignKeyViolation("poll_vote_poll_id_fkey")
+	}
+	if !slices.ContainsFunc(store.data.pollOptions, func(option postgres.PollOption) bool { return option.ID == arg.OptionID }) {
+		return foreignKeyViolation("poll_vote_option_id_fkey")
+	}
+
+	for index := range store.data.pollVotes {
+		vote := &store.data.pollVotes[index]
+		if vote.PollID == arg.PollID && bytes.Equal(vote.ParticipantHash, arg.ParticipantHash) {
+			vote.OptionID = arg.OptionID
+			vote.CreatedAt = now()
+			return nil
+		}
+	}
+
+	store.data.pollVotes = append(store.data.pollVotes, postgres.PollVote{
+		PollID:          arg.PollID,
+		ParticipantHash: arg.ParticipantHash,
+		OptionID:        arg.OptionID,
+		CreatedAt:       now(),
+	})
+
+	return nil
+}
+
+func (store *Store) GetPollOptions(ctx context.Context, pollID uuid.UUID) ([]postgres.GetPollOptionsRow, error) {
+	defer store.lock()()
+
+	var rows []postgres.GetPollOptionsRow
+	for _, option := range store.data.pollOptions {
+		if option.PollID == pollID {
+			rows = append(rows, postgres.GetPollOptionsRow(store.data.countVotes(option)))
+		}
+	}
+
+	return rows, nil
+}
+
+func (store *Store) GetRoomPollOptions(ctx context.Context, roomID uuid.UUID) ([]postgres.GetRoomPollOptionsRow, error) {
+	defer store.lock()()
+
+	var rows []postgres.GetRoomPollOptionsRow
+	for _, option := range store.data.pollOptions {
+		if poll := store.data.poll(option.PollID); poll != nil && poll.RoomID == roomID {
+			rows = append(rows, store.data.countVotes(option))
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b postgres.GetRoomPollOptionsRow) int {
+		if order := bytes.Compare(a.PollID[:], b.PollID[:]); order != 0 {
+			return order
+		}
+
+		return cmp.Compare(a.Position, b.Position)
+	})
+
+	return rows, nil
+}
+
+// deletePolls removes the polls of the room with their options and votes, like
+// the ON DELETE CASCADE on poll.room_id does.
+func (store *Store) deletePolls(roomID uuid.UUID) {
+	store.data.polls = slices.DeleteFunc(store.data.polls, func(poll postgres.Poll) bool {
+		return poll.RoomID == roomID
+	})
+	store.data.pollOptions = slices.DeleteFunc(store.data.pollOptions, func(option postgres.PollOption) bool {
+		return store.data.poll(option.PollID) == nil
+	})
+	store.data.pollVotes = slices.DeleteFunc(store.data.pollVotes, func(vote postgres.PollVote) bool {
+		return store.data.poll(vote.PollID) == nil
+	})
+}
+
+func (data *dataset) poll(id uuid.UUID) *postgres.Poll {
+	for index := range data.polls {
+		if data.polls[index].ID == id {
+			return &data.polls[index]
+		}
+	}
+
+	return nil
+}
+
+func (data *dataset) countVotes(option postgres.PollOption) postgres.GetRoomPollOptionsRow {
+	row := postgres.GetRoomPollOptionsRow{
+		ID:       option.ID,
+		PollID:   option.PollID,
+		Position: option.Position,
+		Text:     option.Text,
+	}
+
+	for _, vote := range data.pollVotes {
+		if vote.OptionID == option.ID {
+			row.VoteCount++
+		}
+	}
+
+	return row
+}
@@ -0,0 +1,285 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func (store *Store) CreateQuestion(ctx context.Context, arg postgres.CreateQuestionParams) (postgres.Question, error) {
+	defer store.lock()()
+
+	if store.data.room(arg.RoomID) == nil {
+		return postgres.Question{}, foreignKeyViolation("question_room_id_fkey")
+	}
+
+	question := postgres.Question{
+		ID:         uuid.New(),
+		RoomID:     arg.RoomID,
+		Text:       arg.Text,
+		CreatedAt:  now(),
+		UpdatedAt:  now(),
+		AuthorHash: arg.AuthorHash,
+		AuthorName: arg.AuthorName,
+	}
+	store.data.questions = append(store.data.questions, question)
+	store.audit("question", question.RoomID, question.ID, "insert", nil, questionColumns(&question))
+
+	return question, nil
+}
+
+// CreateQuestions stands in for the COPY FROM based bulk insert, which fails
+// as a whole when any row references an unknown room.
+func (store *Store) CreateQuestions(ctx context.Context, arg []postgres.CreateQuestionsParams) (int64, error) {
+	defer store.lock()()
+
+	for _, params := range arg {
+		if store.data.room(params.RoomID) == nil {
+			return 0, foreignKeyViolation("question_room_id_fkey")
+		}
+	}
+
+	for _, params := range arg {
+		question := postgres.Question{
+			ID:            params.ID,
+			RoomID:        params.RoomID,
+			Text:          params.Text,
+			ReactionCount: params.ReactionCount,
+			Answered:      params.Answered,
+			CreatedAt:     now(),
+			UpdatedAt:     now(),
+		}
+		store.data.questions = append(store.data.questions, question)
+		store.audit("question", question.RoomID, question.ID, "insert", nil, questionColumns(&question))
+	}
+
+	return int64(len(arg)), nil
+}
+
+func (store *Store) GetQuestion(ctx context.Context, id uuid.UUID) (postgres.Question, error) {
+	defer store.lock()()
+
+	question := store.data.activeQuestion(id)
+	if question == nil {
+		return postgres.Question{}, pgx.ErrNoRows
+	}
+
+	return *question, nil
+}
+
+func (store *Store) GetRoomQuestions(ctx context.Context, roomID uuid.UUID) ([]postgres.Question, error) {
+	defer store.lock()()
+
+	var questions []postgres.Question
+	for _, question := range store.data.questions {
+		if question.RoomID == roomID && !question.DeletedAt.Valid {
+			questions = append(questions, question)
+		}
+	}
+
+	return questions, nil
+}
+
+func (store *Store) GetRoomQuestionsByPopularity(ctx context.Context, roomID uuid.UUID) ([]postgres.Question, error) {
+	questions, _ := store.GetRoomQuestions(ctx, roomID)
+
+	slices.SortStableFunc(questions, func(a, b postgres.Question) int {
+		if a.ReactionCount != b.ReactionCount {
+			return cmp.Compare(b.ReactionCount, a.ReactionCount)
+		}
+
+		return a.CreatedAt.Time.Compare(b.CreatedAt.Time)
+	})
+
+	return questions, nil
+}
+
+func (store *Store) MarkQuestionAsAnswered(ctx context.Context, id uuid.UUID) error {
+	defer store.lock()()
+
+	if question := store.data.question(id); question != nil {
+		store.updateQuestion(question, func(question *postgres.Question) {
+			question.Answered = true
+		})
+	}
+
+	return nil
+}
+
+func (store *Store) ReactToQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	question := store.data.question(id)
+	if question == nil {
+		return 0, pgx.ErrNoRows
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.ReactionCount++
+	})
+
+	return question.ReactionCount, nil
+}
+
+func (store *Store) RemoveReactionFromQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	question := store.data.question(id)
+	if question == nil {
+		return 0, pgx.ErrNoRows
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.ReactionCount--
+	})
+
+	return question.ReactionCount, nil
+}
+
+// UpdateQuestionText only changes questions that are still editable, like the
+// conditions of the UPDATE in queries.sql.
+func (store *Store) UpdateQuestionText(ctx context.Context, arg postgres.UpdateQuestionTextParams) (postgres.Question, error) {
+	defer store.lock()()
+
+	question := store.data.activeQuestion(arg.ID)
+	if question == nil || question.DownvoteCount != 0 || store.data.reacted(question.ID) || question.Answered {
+		return postgres.Question{}, pgx.ErrNoRows
+	}
+
+	window := time.Duration(arg.EditWindowSeconds * float64(time.Second))
+	if question.CreatedAt.Time.Before(now().Time.Add(-window)) {
+		return postgres.Question{}, pgx.ErrNoRows
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.Text = arg.Text
+	})
+
+	return *question, nil
+}
+
+func (store *Store) CreateQuestionEdit(ctx context.Context, arg postgres.CreateQuestionEditParams) error {
+	defer store.lock()()
+
+	if store.data.question(arg.QuestionID) == nil {
+		return foreignKeyViolation("question_edit_question_id_fkey")
+	}
+
+	store.data.edits = append(store.data.edits, postgres.QuestionEdit{
+		ID:         uuid.New(),
+		QuestionID: arg.QuestionID,
+		Text:       arg.Text,
+		EditedAt:   now(),
+	})
+
+	return nil
+}
+
+func (store *Store) GetQuestionEdits(ctx context.Context, questionID uuid.UUID) ([]postgres.QuestionEdit, error) {
+	defer store.lock()()
+
+	var edits []postgres.QuestionEdit
+	for _, edit := range store.data.edits {
+		if edit.QuestionID == questionID {
+			edits = append(edits, edit)
+		}
+	}
+
+	return edits, nil
+}
+
+func (store *Store) SoftDeleteQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	question := store.data.activeQuestion(id)
+	if question == nil {
+		return 0, nil
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.DeletedAt = now()
+	})
+
+	return 1, nil
+}
+
+func (store *Store) DeleteQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	return store.deleteQuestions(func(question postgres.Question) bool {
+		return question.ID == id
+	}), nil
+}
+
+func (store *Store) DeleteRoomQuestions(ctx context.Context, roomID uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	return store.deleteQuestions(func(question postgres.Question) bool {
+		return question.RoomID == roomID
+	}), nil
+}
+
+// deleteQuestions removes the matching questions with their edit history,
+// reactions and comments, like the ON DELETE CASCADE on the question_id of
+// question_edit, question_reaction and comment does, auditing each one. Rooms
+// spotlighting one of them lose their spotlight.
+func (store *Store) deleteQuestions(match func(question postgres.Question) bool) int64 {
+	var deleted int64
+	store.data.questions = slices.DeleteFunc(store.data.questions, func(question postgres.Question) bool {
+		if !match(question) {
+			return false
+		}
+
+		deleted++
+		store.audit("question", question.RoomID, question.ID, "delete", questionColumns(&question), nil)
+		return true
+	})
+
+	store.data.edits = slices.DeleteFunc(store.data.edits, func(edit postgres.QuestionEdit) bool {
+		return store.data.question(edit.QuestionID) == nil
+	})
+	store.data.reactions = slices.DeleteFunc(store.data.reactions, func(reaction postgres.QuestionReaction) bool {
+		return store.data.question(reaction.QuestionID) == nil
+	})
+	store.data.comments = slices.DeleteFunc(store.data.comments, func(comment postgres.Comment) bool {
+		return store.data.question(comment.QuestionID) == nil
+	})
+
+	// ON DELETE SET NULL on room.spotlight_question_id
+	for index := range store.data.rooms {
+		room := &store.data.rooms[index]
+		if room.SpotlightQuestionID.Valid && store.data.question(room.SpotlightQuestionID.Bytes) == nil {
+			store.updateRoom(room, func(room *postgres.Room) {
+				room.SpotlightQuestionID = pgtype.UUID{}
+			})
+		}
+	}
+
+	return deleted
+}
+
+func (data *dataset) question(id uuid.UUID) *postgres.Question {
+	for index := range data.questions {
+		if data.questions[index].ID == id {
+			return &data.questions[index]
+		}
+	}
+
+	return nil
+}
+
+// activeQuestion is like question but skips soft deleted questions.
+func (data *dataset) activeQuestion(id uuid.UUID) *postgres.Question {
+	question := data.question(id)
+	if question == nil || question.DeletedAt.Valid {
+		return nil
+	}
+
+	return question
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// GetRankedRoomQuestions puts the pinned questions first and orders the rest
+// with the same scores the SQL functions of the ranking migration compute.
+func (store *Store) GetRankedRoomQuestions(ctx context.Context, arg postgres.GetRankedRoomQuestionsParams) ([]postgres.Question, error) {
+	defer store.lock()()
+
+	var questions []postgres.Question
+	for _, question := range store.data.questions {
+		if question.RoomID == arg.RoomID && !question.DeletedAt.Valid {
+			questions = append(questions, question)
+		}
+	}
+
+	at := time.Now()
+	score := func(question postgres.Question) float64 {
+		switch arg.Ranking {
+		case "wilson":
+			return wilsonScore(question.ReactionCount, question.DownvoteCount)
+		case "hot":
+			return hotScore(question.ReactionCount, question.DownvoteCount, question.CreatedAt.Time, at)
+		default:
+			return float64(question.ReactionCount - question.DownvoteCount)
+		}
+	}
+
+	slices.SortFunc(questions, func(a, b postgres.Question) int {
+		if order := comparePins(a, b); order != 0 {
+			return order
+		}
+		if order := cmp.Compare(score(b), score(a)); order != 0 {
+			return order
+		}
+		if order := a.CreatedAt.Time.Compare(b.CreatedAt.Time); order != 0 {
+			return order
+		}
+
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	offset := min(int(arg.PageOffset), len(questions))
+	questions = questions[offset:]
+	if arg.PageSize.Valid && int(arg.PageSize.Int32) < len(questions) {
+		questions = questions[:arg.PageSize.Int32]
+	}
+
+	return questions, nil
+}
+
+// wilsonScore mirrors question_wilson_score.
+func wilsonScore(upvotes, downvotes int64) float64 {
+	if upvotes+downvotes <= 0 {
+		return 0
+	}
+
+	n := float64(upvotes + downvotes)
+	p := float64(upvotes) / n
+
+	return (p + 1.9208/n - 1.96*math.Sqrt((p*(1-p)+0.9604/n)/n)) / (1 + 3.8416/n)
+}
+
+// hotScore mirrors question_hot_score.
+func hotScore(upvotes, downvotes int64, createdAt, at time.Time) float64 {
+	hours := max(at.Sub(createdAt).Hours(), 0)
+	return float64(upvotes-downvotes) / math.Pow(hours+2, 1.8)
+}
+
+func (store *Store) DownvoteQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	question := store.data.question(id)
+	if question == nil {
+		return 0, pgx.ErrNoRows
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.DownvoteCount++
+	})
+
+	return question.DownvoteCount, nil
+}
+
+// RemoveDownvoteFromQuestion never takes the count below zero, like the
+// condition of the UPDATE in queries.sql.
+func (store *Store) RemoveDownvoteFromQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	defer store.lock()()
+
+	question := store.data.question(id)
+	if question == nil || question.DownvoteCount == 0 {
+		return 0, pgx.ErrNoRows
+	}
+
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.DownvoteCount--
+	})
+
+	return question.DownvoteCount, nil
+}
+
+func (store *Store) UpdateRoomRanking(ctx context.Context, arg postgres.UpdateRoomRankingParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		room.Ranking = arg.Ranking
+	})
+
+	return *room, nil
+}
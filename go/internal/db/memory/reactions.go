@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func (store *Store) AddQuestionReaction(ctx context.Context, arg postgres.AddQuestionReactionParams) (int64, error) {
+	defer store.lock()()
+
+	if store.data.question(arg.QuestionID) == nil {
+		return 0, foreignKeyViolation("question_reaction_question_id_fkey")
+	}
+
+	if reaction := store.data.reaction(arg.QuestionID, arg.Kind); reaction != nil {
+		reaction.Count++
+		return reaction.Count, nil
+	}
+
+	store.data.reactions = append(store.data.reactions, postgres.QuestionReaction{
+		QuestionID: arg.QuestionID,
+		Kind:       arg.Kind,
+		Count:      1,
+	})
+
+	return 1, nil
+}
+
+// RemoveQuestionReaction never takes a count below zero, like the condition of
+// the UPDATE in queries.sql.
+func (store *Store) RemoveQuestionReaction(ctx context.Context, arg postgres.RemoveQuestionReactionParams) (int64, error) {
+	defer store.lock()()
+
+	reaction := store.data.reaction(arg.QuestionID, arg.Kind)
+	if reaction == nil || reaction.Count == 0 {
+		return 0, pgx.ErrNoRows
+	}
+
+	reaction.Count--
+	return reaction.Count, nil
+}
+
+func (store *Store) GetQuestionReactions(ctx context.Context, questionID uuid.UUID) ([]postgres.QuestionReaction, error) {
+	defer store.lock()()
+
+	var reactions []postgres.QuestionReaction
+	for _, reaction := range store.data.reactions {
+		if reaction.QuestionID == questionID {
+			reactions = append(reactions, reaction)
+		}
+	}
+
+	return reactions, nil
+}
+
+func (store *Store) GetRoomQuestionReactions(ctx context.Context, roomID uuid.UUID) ([]postgres.QuestionReaction, error) {
+	defer store.lock()()
+
+	var reactions []postgres.QuestionReaction
+	for _, reaction := range store.data.reactions {
+		question := store.data.activeQuestion(reaction.QuestionID)
+		if question != nil && question.RoomID == roomID {
+			reactions = append(reactions, reaction)
+		}
+	}
+
+	return reactions, nil
+}
+
+func (store *Store) CreateQuestionReactionsFromCounts(ctx context.Context, arg postgres.CreateQuestionReactionsFromCountsParams) error {
+	defer store.lock()()
+
+	for _, question := range store.data.questions {
+		if question.RoomID != arg.RoomID || question.ReactionCount <= 0 {
+			continue
+		}
+
+		// ON CONFLICT DO NOTHING
+		if store.data.reaction(question.ID, arg.Kind) != nil {
+			continue
+		}
+
+		store.data.reactions = append(store.data.reactions, postgres.QuestionReaction{
+			QuestionID: question.ID,
+			Kind:       arg.Kind,
+			Count:      question.ReactionCount,
+		})
+	}
+
+	return nil
+}
+
+func (store *Store) SetQuestionReactionCounts(ctx context.Context, arg postgres.SetQuestionReactionCountsParams) error {
+	defer store.lock()()
+
+	for index := range store.data.questions {
+		question := &store.data.questions[index]
+		if question.RoomID != arg.RoomID {
+			continue
+		}
+
+		var count int64
+		if reaction := store.data.reaction(question.ID, arg.Kind); reaction != nil {
+			count = reaction.Count
+		}
+
+		store.updateQuestion(question, func(question *postgres.Question) {
+			question.ReactionCount = count
+		})
+	}
+
+	return nil
+}
+
+func (data *dataset) reaction(questionID uuid.UUID, kind string) *postgres.QuestionReaction {
+	for index := range data.reactions {
+		if data.reactions[index].QuestionID == questionID && data.reactions[index].Kind == kind {
+			return &data.reactions[index]
+		}
+	}
+
+	return nil
+}
+
+// reacted tells whether the question has any reaction of any kind.
+func (data *dataset) reacted(questionID uuid.UUID) bool {
+	for _, reaction := range data.reactions {
+		if reaction.QuestionID == questionID && reaction.Count > 0 {
+			return true
+		}
+	}
+
+	return false
+}
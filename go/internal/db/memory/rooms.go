@@ -0,0 +1,422 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func (store *Store) CreateRoom(ctx context.Context, arg postgres.CreateRoomParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	if store.data.organization(arg.OrganizationID) == nil {
+		return postgres.Room{}, foreignKeyViolation("room_organization_id_fkey")
+	}
+
+	for _, room := range store.data.rooms {
+		if room.Slug == arg.Slug && room.OrganizationID == arg.OrganizationID {
+			return postgres.Room{}, uniqueViolation("room_slug_idx")
+		}
+	}
+
+	room := postgres.Room{
+		ID:              uuid.New(),
+		Name:            arg.Name,
+		CreatedAt:       now(),
+		UpdatedAt:       now(),
+		OwnerTokenHash:  arg.OwnerTokenHash,
+		Visibility:      arg.Visibility,
+		JoinCode:        arg.JoinCode,
+		Slug:            arg.Slug,
+		ReactionKinds:   arg.ReactionKinds,
+		PrimaryReaction: arg.PrimaryReaction,
+		Ranking:         arg.Ranking,
+		Anonymous:       arg.Anonymous,
+		OrganizationID:  arg.OrganizationID,
+	}
+	store.data.rooms = append(store.data.rooms, room)
+	store.audit("room", room.ID, room.ID, "insert", nil, roomColumns(&room))
+
+	return room, nil
+}
+
+func (store *Store) GetRoom(ctx context.Context, arg postgres.GetRoomParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.activeRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	return *room, nil
+}
+
+func (store *Store) GetRoomBySlug(ctx context.Context, arg postgres.GetRoomBySlugParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	for _, room := range store.data.rooms {
+		if room.Slug == arg.Slug && room.OrganizationID == arg.OrganizationID && !room.DeletedAt.Valid {
+			return room, nil
+		}
+	}
+
+	return postgres.Room{}, pgx.ErrNoRows
+}
+
+func (store *Store) GetRooms(ctx context.Context, organizationID uuid.UUID) ([]postgres.Room, error) {
+	defer store.lock()()
+
+	var rooms []postgres.Room
+	for _, room := range store.data.rooms {
+		if room.OrganizationID == organizationID && !room.DeletedAt.Valid {
+			rooms = append(rooms, room)
+		}
+	}
+
+	return rooms, nil
+}
+
+func (store *Store) GetRoomsWithQuestionCount(ctx context.Context, arg postgres.GetRoomsWithQuestionCountParams) ([]postgres.GetRoomsWithQuestionCountRow, error) {
+	defer store.lock()()
+
+	var rows []postgres.GetRoomsWithQuestionCountRow
+	for _, room := range store.data.rooms {
+		if room.OrganizationID != arg.OrganizationID || (room.DeletedAt.Valid && !arg.IncludeDeleted) {
+			continue
+		}
+
+		row := postgres.GetRoomsWithQuestionCountRow{
+			ID:                  room.ID,
+			Name:                room.Name,
+			CreatedAt:           room.CreatedAt,
+			UpdatedAt:           room.UpdatedAt,
+			ClosedAt:            room.ClosedAt,
+			OwnerTokenHash:      room.OwnerTokenHash,
+			DeletedAt:           room.DeletedAt,
+			Visibility:          room.Visibility,
+			JoinCode:            room.JoinCode,
+			Slug:                room.Slug,
+			ReactionKinds:       room.ReactionKinds,
+			PrimaryReaction:     room.PrimaryReaction,
+			Ranking:             room.Ranking,
+			SpotlightQuestionID: room.SpotlightQuestionID,
+			SpotlightedAt:       room.SpotlightedAt,
+			Anonymous:           room.Anonymous,
+			OrganizationID:      room.OrganizationID,
+		}
+
+		for _, question := range store.data.questions {
+			if question.RoomID == room.ID && !question.DeletedAt.Valid {
+				row.QuestionCount++
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func (store *Store) CloseRoom(ctx context.Context, arg postgres.CloseRoomParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		if !room.ClosedAt.Valid {
+			room.ClosedAt = now()
+		}
+	})
+
+	return *room, nil
+}
+
+func (store *Store) ReopenRoom(ctx context.Context, arg postgres.ReopenRoomParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		room.ClosedAt = pgtype.Timestamptz{}
+	})
+
+	return *room, nil
+}
+
+func (store *Store) UpdateRoomReactions(ctx context.Context, arg postgres.UpdateRoomReactionsParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		room.ReactionKinds = arg.ReactionKinds
+		room.PrimaryReaction = arg.PrimaryReaction
+	})
+
+	return *room, nil
+}
+
+func (store *Store) UpdateRoomAnonymity(ctx context.Context, arg postgres.UpdateRoomAnonymityParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		room.Anonymous = arg.Anonymous
+	})
+
+	return *room, nil
+}
+
+func (store *Store) SoftDeleteRoom(ctx context.Context, arg postgres.SoftDeleteRoomParams) (int64, error) {
+	defer store.lock()()
+
+	room := store.data.activeRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return 0, nil
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		room.DeletedAt = now()
+	})
+
+	return 1, nil
+}
+
+// DeleteRoom removes the room along with its questions, like the ON DELETE
+// CASCADE on question.room_id does.
+func (store *Store) DeleteRoom(ctx context.Context, arg postgres.DeleteRoomParams) (int64, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return 0, nil
+	}
+	id := arg.ID
+
+	deleted := *room
+	store.data.rooms = slices.DeleteFunc(store.data.rooms, func(room postgres.Room) bool {
+		return room.ID == id
+	})
+	store.audit("room", id, id, "delete", roomColumns(&deleted), nil)
+	store.data.grants = slices.DeleteFunc(store.data.grants, func(grant postgres.RoomGrant) bool {
+		return grant.RoomID == id
+	})
+	store.deleteQuestions(func(question postgres.Question) bool {
+		return question.RoomID == id
+	})
+	store.deletePolls(id)
+
+	return 1, nil
+}
+
+func (store *Store) CreateRoomGrant(ctx context.Context, arg postgres.CreateRoomGrantParams) error {
+	defer store.lock()()
+
+	if store.data.room(arg.RoomID) == nil {
+		return foreignKeyViolation("room_grant_room_id_fkey")
+	}
+
+	// ON CONFLICT DO NOTHING
+	if store.data.hasGrant(arg.RoomID, arg.ParticipantHash) {
+		return nil
+	}
+
+	store.data.grants = append(store.data.grants, postgres.RoomGrant{
+		RoomID:          arg.RoomID,
+		ParticipantHash: arg.ParticipantHash,
+		CreatedAt:       now(),
+	})
+
+	return nil
+}
+
+func (store *Store) HasRoomGrant(ctx context.Context, arg postgres.HasRoomGrantParams) (bool, error) {
+	defer store.lock()()
+
+	return store.data.hasGrant(arg.RoomID, arg.ParticipantHash), nil
+}
+
+func (data *dataset) hasGrant(roomID uuid.UUID, participantHash []byte) bool {
+	return slices.ContainsFunc(data.grants, func(grant postgres.RoomGrant) bool {
+		return grant.RoomID == roomID && bytes.Equal(grant.ParticipantHash, participantHash)
+	})
+}
+
+func (data *dataset) room(id uuid.UUID) *postgres.Room {
+	for index := range data.rooms {
+		if data.rooms[index].ID == id {
+			return &data.rooms[index]
+		}
+	}
+
+	return nil
+}
+
+// organizationRoom is like room but only finds the rooms of the organization.
+func (data *dataset) organizationRoom(id, organizationID uuid.UUID) *postgres.Room {
+	room := data.room(id)
+	if room == nil || room.OrganizationID != organizationID {
+		return nil
+	}
+
+	return room
+}
+
+// activeRoom is like organizationRoom but skips soft deleted rooms.
+func (data *dataset) activeRoom(id, organizationID uuid.UUID) *postgres.Room {
+	room := data.organizationRoom(id, organizationID)
+	if room == nil || room.DeletedAt.Valid {
+		return nil
+	}
+
+	return room
+}
+
+func (store *Store) ListRoomsByNewest(ctx context.Context, arg postgres.ListRoomsByNewestParams) ([]postgres.ListRoomsByNewestRow, error) {
+	defer store.lock()()
+
+	listings := store.data.roomListings(arg.OrganizationID, arg.Search, arg.Status)
+	slices.SortFunc(listings, func(a, b roomListing) int {
+		if order := b.room.CreatedAt.Time.Compare(a.room.CreatedAt.Time); order != 0 {
+			return order
+		}
+
+		return bytes.Compare(b.room.ID[:], a.room.ID[:])
+	})
+
+	var rows []postgres.ListRoomsByNewestRow
+	for _, listing := range listings {
+		if arg.After && !listing.before(arg.CursorCreatedAt.Time, arg.CursorID) {
+			continue
+		}
+		if len(rows) == int(arg.PageSize) {
+			break
+		}
+
+		rows = append(rows, postgres.ListRoomsByNewestRow{
+			Room:            listing.room,
+			QuestionCount:   listing.questionCount,
+			UnansweredCount: listing.unansweredCount,
+		})
+	}
+
+	return rows, nil
+}
+
+func (store *Store) ListRoomsByActivity(ctx context.Context, arg postgres.ListRoomsByActivityParams) ([]postgres.ListRoomsByActivityRow, error) {
+	defer store.lock()()
+
+	listings := store.data.roomListings(arg.OrganizationID, arg.Search, arg.Status)
+	slices.SortFunc(listings, func(a, b roomListing) int {
+		if order := cmp.Compare(b.questionCount, a.questionCount); order != 0 {
+			return order
+		}
+
+		return bytes.Compare(b.room.ID[:], a.room.ID[:])
+	})
+
+	var rows []postgres.ListRoomsByActivityRow
+	for _, listing := range listings {
+		if arg.After && !listing.lessActive(arg.CursorQuestionCount, arg.CursorID) {
+			continue
+		}
+		if len(rows) == int(arg.PageSize) {
+			break
+		}
+
+		rows = append(rows, postgres.ListRoomsByActivityRow{
+			Room:            listing.room,
+			QuestionCount:   listing.questionCount,
+			UnansweredCount: listing.unansweredCount,
+		})
+	}
+
+	return rows, nil
+}
+
+type roomListing struct {
+	room            postgres.Room
+	questionCount   int64
+	unansweredCount int64
+}
+
+// before and lessActive compare against a cursor the way the row comparisons
+// in the listing queries do.
+func (listing roomListing) before(createdAt time.Time, id uuid.UUID) bool {
+	if order := listing.room.CreatedAt.Time.Compare(createdAt); order != 0 {
+		return order < 0
+	}
+
+	return bytes.Compare(listing.room.ID[:], id[:]) < 0
+}
+
+func (listing roomListing) lessActive(questionCount int64, id uuid.UUID) bool {
+	if listing.questionCount != questionCount {
+		return listing.questionCount < questionCount
+	}
+
+	return bytes.Compare(listing.room.ID[:], id[:]) < 0
+}
+
+// roomListings filters the rooms like the WHERE clause of the listing queries
+// and counts their questions.
+func (data *dataset) roomListings(organizationID uuid.UUID, search, status string) []roomListing {
+	var listings []roomListing
+	for _, room := range data.rooms {
+		if room.OrganizationID != organizationID || room.DeletedAt.Valid || room.Visibility != "public" || !containsFold(room.Name, search) {
+			continue
+		}
+		if status != "" && (status == "open") != !room.ClosedAt.Valid {
+			continue
+		}
+
+		listing := roomListing{room: room}
+		for _, question := range data.questions {
+			if question.RoomID == room.ID && !question.DeletedAt.Valid {
+				listing.questionCount++
+				if !question.Answered {
+					listing.unansweredCount++
+				}
+			}
+		}
+
+		listings = append(listings, listing)
+	}
+
+	return listings
+}
+
+// containsFold mirrors ILIKE '%' || pattern || '%' for patterns whose
+// wildcards were escaped with a backslash, as the service does.
+func containsFold(value, pattern string) bool {
+	var literal strings.Builder
+	for index := 0; index < len(pattern); index++ {
+		if pattern[index] == '\\' && index+1 < len(pattern) {
+			index++
+		}
+		literal.WriteByte(pattern[index])
+	}
+
+	return strings.Contains(strings.ToLower(value), strings.ToLower(literal.String()))
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// SearchRoomQuestions approximates the full-text search of the query: every
+// word of the search has to appear in the question, compared without stemming,
+// and matches are wrapped in <mark> in the escaped text.
+func (store *Store) SearchRoomQuestions(ctx context.Context, arg postgres.SearchRoomQuestionsParams) ([]postgres.SearchRoomQuestionsRow, error) {
+	defer store.lock()()
+
+	terms := searchWords(arg.Query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var rows []postgres.SearchRoomQuestionsRow
+	for _, question := range store.data.questions {
+		if question.RoomID != arg.RoomID || question.DeletedAt.Valid {
+			continue
+		}
+
+		words := searchWords(question.Text)
+		matches := 0
+		for _, word := range words {
+			if slices.Contains(terms, word) {
+				matches++
+			}
+		}
+
+		if !containsAll(words, terms) {
+			continue
+		}
+
+		rows = append(rows, postgres.SearchRoomQuestionsRow{
+			ID:            question.ID,
+			Text:          question.Text,
+			ReactionCount: question.ReactionCount,
+			Answered:      question.Answered,
+			CreatedAt:     question.CreatedAt,
+			Rank:          float32(matches) / float32(len(words)),
+			Snippet:       highlight(question.Text, terms),
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b postgres.SearchRoomQuestionsRow) int {
+		if order := cmp.Compare(b.Rank, a.Rank); order != 0 {
+			return order
+		}
+
+		return b.CreatedAt.Time.Compare(a.CreatedAt.Time)
+	})
+
+	if len(rows) > int(arg.PageSize) {
+		rows = rows[:arg.PageSize]
+	}
+
+	return rows, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !isWordRune(r) })
+}
+
+func containsAll(words, terms []string) bool {
+	for _, term := range terms {
+		if !slices.Contains(words, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func highlight(text string, terms []string) string {
+	var snippet strings.Builder
+
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && isWordRune(runes[end]) == isWordRune(runes[start]) {
+			end++
+		}
+
+		chunk := string(runes[start:end])
+		if isWordRune(runes[start]) && slices.Contains(terms, strings.ToLower(chunk)) {
+			snippet.WriteString("<mark>" + html.EscapeString(chunk) + "</mark>")
+		} else {
+			snippet.WriteString(html.EscapeString(chunk))
+		}
+
+		start = end
+	}
+
+	return snippet.String()
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// AddSpotlightTime adds the time the room's spotlighted question has spent in
+// the spotlight so far to its spotlight_seconds.
+func (store *Store) AddSpotlightTime(ctx context.Context, arg postgres.AddSpotlightTimeParams) error {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil || !room.SpotlightQuestionID.Valid {
+		return nil
+	}
+
+	question := store.data.question(room.SpotlightQuestionID.Bytes)
+	if question == nil {
+		return nil
+	}
+
+	seconds := max(time.Since(room.SpotlightedAt.Time).Seconds(), 0)
+	store.updateQuestion(question, func(question *postgres.Question) {
+		question.SpotlightSeconds += seconds
+	})
+
+	return nil
+}
+
+func (store *Store) SetRoomSpotlight(ctx context.Context, arg postgres.SetRoomSpotlightParams) (postgres.Room, error) {
+	defer store.lock()()
+
+	room := store.data.organizationRoom(arg.ID, arg.OrganizationID)
+	if room == nil {
+		return postgres.Room{}, pgx.ErrNoRows
+	}
+
+	if arg.QuestionID.Valid && store.data.question(arg.QuestionID.Bytes) == nil {
+		return postgres.Room{}, foreignKeyViolation("room_spotlight_question_id_fkey")
+	}
+
+	store.updateRoom(room, func(room *postgres.Room) {
+		room.SpotlightQuestionID = arg.QuestionID
+		room.SpotlightedAt = pgtype.Timestamptz{}
+		if arg.QuestionID.Valid {
+			room.SpotlightedAt = now()
+		}
+	})
+
+	return *room, nil
+}
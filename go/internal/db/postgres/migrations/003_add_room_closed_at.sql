@@ -0,0 +1,5 @@
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "closed_at" TIMESTAMP;
+
+---- create above / drop below ----
+
+ALTER TABLE room DROP COLUMN IF EXISTS "closed_at";
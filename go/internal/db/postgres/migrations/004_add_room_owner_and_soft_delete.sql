@@ -0,0 +1,9 @@
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "owner_token_hash" BYTEA;
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMP;
+ALTER TABLE question ADD COLUMN IF NOT EXISTS "deleted_at" TIMESTAMP;
+
+---- create above / drop below ----
+
+ALTER TABLE question DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE room DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE room DROP COLUMN IF EXISTS "owner_token_hash";
@@ -0,0 +1,17 @@
+ALTER TABLE question ADD COLUMN IF NOT EXISTS "author_hash" BYTEA;
+
+CREATE TABLE IF NOT EXISTS question_edit (
+    "id"          uuid PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "question_id" uuid             NOT NULL,
+    "text"        VARCHAR(255)     NOT NULL,
+    "edited_at"   TIMESTAMP        NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (question_id) REFERENCES question (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS question_edit_question_id_idx ON question_edit ("question_id", "edited_at");
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS question_edit;
+ALTER TABLE question DROP COLUMN IF EXISTS "author_hash";
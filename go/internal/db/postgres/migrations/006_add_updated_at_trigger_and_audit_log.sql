@@ -0,0 +1,84 @@
+-- Keeps "updated_at" current on every update that actually changes a row.
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW."updated_at" = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER room_set_updated_at
+    BEFORE UPDATE ON room
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+    EXECUTE FUNCTION set_updated_at();
+
+CREATE TRIGGER question_set_updated_at
+    BEFORE UPDATE ON question
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+    EXECUTE FUNCTION set_updated_at();
+
+-- Rows outlive the rooms they belong to, so there is no foreign key.
+CREATE TABLE IF NOT EXISTS audit_log (
+    "id"         BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    "room_id"    uuid             NOT NULL,
+    "entity"     TEXT             NOT NULL CHECK ("entity" IN ('room', 'question')),
+    "entity_id"  uuid             NOT NULL,
+    "action"     TEXT             NOT NULL CHECK ("action" IN ('insert', 'update', 'delete')),
+    "actor"      TEXT             NOT NULL,
+    "changes"    JSONB            NOT NULL,
+    "created_at" TIMESTAMP        NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_room_id_idx ON audit_log ("room_id", "id");
+
+-- Records every change to a room or question along with the actor the
+-- application set for the transaction through the ama.actor setting. Secrets,
+-- timestamps maintained by triggers and reaction counts are left out, and an
+-- update that changes nothing else isn't recorded at all.
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'owner_token_hash', 'author_hash')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER room_audit
+    AFTER INSERT OR UPDATE OR DELETE ON room
+    FOR EACH ROW EXECUTE FUNCTION audit_change();
+
+CREATE TRIGGER question_audit
+    AFTER INSERT OR UPDATE OR DELETE ON question
+    FOR EACH ROW EXECUTE FUNCTION audit_change();
+
+---- create above / drop below ----
+
+DROP TRIGGER IF EXISTS question_audit ON question;
+DROP TRIGGER IF EXISTS room_audit ON room;
+DROP FUNCTION IF EXISTS audit_change();
+DROP TABLE IF EXISTS audit_log;
+DROP TRIGGER IF EXISTS question_set_updated_at ON question;
+DROP TRIGGER IF EXISTS room_set_updated_at ON room;
+DROP FUNCTION IF EXISTS set_updated_at();
@@ -0,0 +1,36 @@
+-- Existing values carry no zone and are interpreted as UTC.
+ALTER TABLE room
+    ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "closed_at" TYPE TIMESTAMPTZ USING "closed_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "deleted_at" TYPE TIMESTAMPTZ USING "deleted_at" AT TIME ZONE 'UTC';
+
+ALTER TABLE question
+    ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "updated_at" TYPE TIMESTAMPTZ USING "updated_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "deleted_at" TYPE TIMESTAMPTZ USING "deleted_at" AT TIME ZONE 'UTC';
+
+ALTER TABLE question_edit
+    ALTER COLUMN "edited_at" TYPE TIMESTAMPTZ USING "edited_at" AT TIME ZONE 'UTC';
+
+ALTER TABLE audit_log
+    ALTER COLUMN "created_at" TYPE TIMESTAMPTZ USING "created_at" AT TIME ZONE 'UTC';
+
+---- create above / drop below ----
+
+ALTER TABLE audit_log
+    ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE 'UTC';
+
+ALTER TABLE question_edit
+    ALTER COLUMN "edited_at" TYPE TIMESTAMP USING "edited_at" AT TIME ZONE 'UTC';
+
+ALTER TABLE question
+    ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "deleted_at" TYPE TIMESTAMP USING "deleted_at" AT TIME ZONE 'UTC';
+
+ALTER TABLE room
+    ALTER COLUMN "created_at" TYPE TIMESTAMP USING "created_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "updated_at" TYPE TIMESTAMP USING "updated_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "closed_at" TYPE TIMESTAMP USING "closed_at" AT TIME ZONE 'UTC',
+    ALTER COLUMN "deleted_at" TYPE TIMESTAMP USING "deleted_at" AT TIME ZONE 'UTC';
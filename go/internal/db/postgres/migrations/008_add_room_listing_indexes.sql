@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS room_created_at_id_idx ON room ("created_at" DESC, "id" DESC) WHERE "deleted_at" IS NULL;
+CREATE INDEX IF NOT EXISTS question_room_id_idx ON question ("room_id") WHERE "deleted_at" IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS question_room_id_idx;
+DROP INDEX IF EXISTS room_created_at_id_idx;
@@ -0,0 +1,72 @@
+ALTER TABLE question ADD COLUMN IF NOT EXISTS "search_vector" tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', "text")) STORED;
+
+CREATE INDEX IF NOT EXISTS question_search_vector_idx ON question USING GIN ("search_vector");
+
+-- The search vector is derived from the text, so auditing it adds nothing.
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'owner_token_hash', 'author_hash', 'search_vector')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+---- create above / drop below ----
+
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'owner_token_hash', 'author_hash')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS question_search_vector_idx;
+ALTER TABLE question DROP COLUMN IF EXISTS "search_vector";
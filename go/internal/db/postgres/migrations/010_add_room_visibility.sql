@@ -0,0 +1,22 @@
+ALTER TABLE room
+    ADD COLUMN IF NOT EXISTS "visibility" TEXT NOT NULL DEFAULT 'public'
+        CHECK ("visibility" IN ('public', 'unlisted', 'private')),
+    ADD COLUMN IF NOT EXISTS "join_code" TEXT;
+
+-- Participants who entered a private room with its join code, recognized by
+-- the hash of their participant ID from then on.
+CREATE TABLE IF NOT EXISTS room_grant (
+    "room_id"          uuid        NOT NULL,
+    "participant_hash" BYTEA       NOT NULL,
+    "created_at"       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY ("room_id", "participant_hash"),
+    FOREIGN KEY (room_id) REFERENCES room (id) ON DELETE CASCADE
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS room_grant;
+ALTER TABLE room
+    DROP COLUMN IF EXISTS "join_code",
+    DROP COLUMN IF EXISTS "visibility";
@@ -0,0 +1,19 @@
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "slug" TEXT;
+
+-- Existing rooms get their name in URL form, made unique by the start of
+-- their ID.
+UPDATE room
+SET "slug" = COALESCE(
+        NULLIF(left(trim(BOTH '-' FROM regexp_replace(lower("name"), '[^a-z0-9]+', '-', 'g')), 40), ''),
+        'room'
+    ) || '-' || left(replace("id"::text, '-', ''), 8)
+WHERE "slug" IS NULL;
+
+ALTER TABLE room ALTER COLUMN "slug" SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS room_slug_idx ON room ("slug");
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS room_slug_idx;
+ALTER TABLE room DROP COLUMN IF EXISTS "slug";
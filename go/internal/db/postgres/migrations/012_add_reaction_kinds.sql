@@ -0,0 +1,32 @@
+-- Rooms choose the kinds of reactions they offer. The primary one ranks the
+-- questions, so question.reaction_count keeps the count of that kind.
+ALTER TABLE room
+    ADD COLUMN IF NOT EXISTS "reaction_kinds" TEXT[] NOT NULL DEFAULT '{upvote}',
+    ADD COLUMN IF NOT EXISTS "primary_reaction" TEXT NOT NULL DEFAULT 'upvote';
+
+ALTER TABLE room ADD CONSTRAINT room_primary_reaction_check
+    CHECK ("primary_reaction" = ANY ("reaction_kinds"));
+
+CREATE TABLE IF NOT EXISTS question_reaction (
+    "question_id" uuid   NOT NULL,
+    "kind"        TEXT   NOT NULL,
+    "count"       BIGINT NOT NULL DEFAULT 0 CHECK ("count" >= 0),
+
+    PRIMARY KEY ("question_id", "kind"),
+    FOREIGN KEY (question_id) REFERENCES question (id) ON DELETE CASCADE
+);
+
+-- Every reaction so far was an upvote.
+INSERT INTO question_reaction ("question_id", "kind", "count")
+SELECT "id", 'upvote', "reaction_count"
+FROM question
+WHERE "reaction_count" > 0
+ON CONFLICT DO NOTHING;
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS question_reaction;
+ALTER TABLE room
+    DROP CONSTRAINT IF EXISTS room_primary_reaction_check,
+    DROP COLUMN IF EXISTS "primary_reaction",
+    DROP COLUMN IF EXISTS "reaction_kinds";
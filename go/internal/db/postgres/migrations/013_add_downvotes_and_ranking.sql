@@ -0,0 +1,93 @@
+ALTER TABLE question ADD COLUMN IF NOT EXISTS "downvote_count" BIGINT NOT NULL DEFAULT 0
+    CHECK ("downvote_count" >= 0);
+
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "ranking" TEXT NOT NULL DEFAULT 'score'
+    CHECK ("ranking" IN ('score', 'wilson', 'hot'));
+
+-- Lower bound of the Wilson score confidence interval at 95% for the share of
+-- upvotes, so a few votes rank below many votes with the same ratio.
+CREATE OR REPLACE FUNCTION question_wilson_score(upvotes BIGINT, downvotes BIGINT)
+RETURNS DOUBLE PRECISION AS $$
+    SELECT CASE WHEN upvotes + downvotes <= 0 THEN 0 ELSE (
+        p + 1.9208 / n - 1.96 * sqrt((p * (1 - p) + 0.9604 / n) / n)
+    ) / (1 + 3.8416 / n) END
+    FROM (SELECT upvotes::float8 / (upvotes + downvotes) AS p, (upvotes + downvotes)::float8 AS n) AS votes
+$$ LANGUAGE sql IMMUTABLE;
+
+-- The score decays with the age of the question, the way Hacker News ranks
+-- stories, so recent questions get a chance to rise.
+CREATE OR REPLACE FUNCTION question_hot_score(upvotes BIGINT, downvotes BIGINT, created_at TIMESTAMPTZ, at TIMESTAMPTZ)
+RETURNS DOUBLE PRECISION AS $$
+    SELECT (upvotes - downvotes)::float8
+        / power(GREATEST(EXTRACT(EPOCH FROM at - created_at) / 3600, 0) + 2, 1.8)
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Downvotes are counts like reactions, which aren't audited either.
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'downvote_count', 'owner_token_hash', 'author_hash', 'search_vector')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+---- create above / drop below ----
+
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'owner_token_hash', 'author_hash', 'search_vector')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS question_hot_score(BIGINT, BIGINT, TIMESTAMPTZ, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS question_wilson_score(BIGINT, BIGINT);
+ALTER TABLE room DROP COLUMN IF EXISTS "ranking";
+ALTER TABLE question DROP COLUMN IF EXISTS "downvote_count";
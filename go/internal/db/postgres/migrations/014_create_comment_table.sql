@@ -0,0 +1,17 @@
+-- Follow-up comments add context to a question instead of a near-duplicate
+-- one. They go away with their question.
+CREATE TABLE IF NOT EXISTS comment (
+    "id"          uuid PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "question_id" uuid             NOT NULL,
+    "text"        VARCHAR(255)     NOT NULL,
+    "author_hash" BYTEA,
+    "created_at"  TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (question_id) REFERENCES question (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS comment_question_id_idx ON comment ("question_id", "created_at");
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS comment;
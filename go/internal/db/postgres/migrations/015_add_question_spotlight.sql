@@ -0,0 +1,78 @@
+-- The owner puts the question being answered in the spotlight. The time a
+-- question spends there adds up in its spotlight_seconds once it leaves.
+ALTER TABLE room
+    ADD COLUMN IF NOT EXISTS "spotlight_question_id" uuid REFERENCES question (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS "spotlighted_at" TIMESTAMPTZ;
+
+ALTER TABLE question ADD COLUMN IF NOT EXISTS "spotlight_seconds" DOUBLE PRECISION NOT NULL DEFAULT 0
+    CHECK ("spotlight_seconds" >= 0);
+
+-- Time on a question is tracked like the counts, which aren't audited either.
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'downvote_count', 'spotlight_seconds', 'owner_token_hash', 'author_hash', 'search_vector')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+---- create above / drop below ----
+
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+    old_row  JSONB := CASE WHEN TG_OP = 'INSERT' THEN NULL ELSE to_jsonb(OLD) END;
+    new_row  JSONB := CASE WHEN TG_OP = 'DELETE' THEN NULL ELSE to_jsonb(NEW) END;
+    row_data JSONB := COALESCE(new_row, old_row);
+    changes  JSONB;
+BEGIN
+    SELECT jsonb_object_agg(key, jsonb_build_object('old', old_row -> key, 'new', new_row -> key))
+    INTO changes
+    FROM jsonb_object_keys(row_data) AS key
+    WHERE key NOT IN ('updated_at', 'reaction_count', 'downvote_count', 'owner_token_hash', 'author_hash', 'search_vector')
+      AND (old_row -> key) IS DISTINCT FROM (new_row -> key);
+
+    IF changes IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO audit_log ("room_id", "entity", "entity_id", "action", "actor", "changes")
+    VALUES (
+        CASE WHEN TG_TABLE_NAME = 'room' THEN row_data ->> 'id' ELSE row_data ->> 'room_id' END::uuid,
+        TG_TABLE_NAME,
+        (row_data ->> 'id')::uuid,
+        lower(TG_OP),
+        COALESCE(NULLIF(current_setting('ama.actor', true), ''), 'system'),
+        changes
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE question DROP COLUMN IF EXISTS "spotlight_seconds";
+ALTER TABLE room
+    DROP COLUMN IF EXISTS "spotlighted_at",
+    DROP COLUMN IF EXISTS "spotlight_question_id";
@@ -0,0 +1,19 @@
+-- Owners pin questions to the top of the room in an order of their own,
+-- whatever the votes say.
+ALTER TABLE question
+    ADD COLUMN IF NOT EXISTS "pinned_at" TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS "pin_position" INTEGER;
+
+ALTER TABLE question ADD CONSTRAINT question_pin_check
+    CHECK (("pinned_at" IS NULL) = ("pin_position" IS NULL));
+
+CREATE INDEX IF NOT EXISTS question_pinned_idx ON question ("room_id", "pin_position")
+    WHERE "pinned_at" IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS question_pinned_idx;
+ALTER TABLE question
+    DROP CONSTRAINT IF EXISTS question_pin_check,
+    DROP COLUMN IF EXISTS "pin_position",
+    DROP COLUMN IF EXISTS "pinned_at";
@@ -0,0 +1,42 @@
+-- Owners run quick polls in their rooms. Every participant has a single vote
+-- per poll, which they can move to another option while the poll is open.
+CREATE TABLE IF NOT EXISTS poll (
+    "id"         uuid PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "room_id"    uuid             NOT NULL,
+    "question"   VARCHAR(255)     NOT NULL,
+    "created_at" TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    "closed_at"  TIMESTAMPTZ,
+
+    FOREIGN KEY (room_id) REFERENCES room (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS poll_room_id_idx ON poll ("room_id", "created_at");
+
+CREATE TABLE IF NOT EXISTS poll_option (
+    "id"       uuid PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "poll_id"  uuid             NOT NULL,
+    "position" INTEGER          NOT NULL,
+    "text"     VARCHAR(255)     NOT NULL,
+
+    UNIQUE ("poll_id", "position"),
+    FOREIGN KEY (poll_id) REFERENCES poll (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS poll_vote (
+    "poll_id"          uuid        NOT NULL,
+    "participant_hash" BYTEA       NOT NULL,
+    "option_id"        uuid        NOT NULL,
+    "created_at"       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY ("poll_id", "participant_hash"),
+    FOREIGN KEY (poll_id) REFERENCES poll (id) ON DELETE CASCADE,
+    FOREIGN KEY (option_id) REFERENCES poll_option (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS poll_vote_option_id_idx ON poll_vote ("option_id");
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS poll_vote;
+DROP TABLE IF EXISTS poll_option;
+DROP TABLE IF EXISTS poll;
@@ -0,0 +1,10 @@
+-- Participants can sign their questions with a display name, unless the room
+-- owner keeps the room anonymous.
+ALTER TABLE question ADD COLUMN IF NOT EXISTS "author_name" VARCHAR(64);
+
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "anonymous" BOOLEAN NOT NULL DEFAULT false;
+
+---- create above / drop below ----
+
+ALTER TABLE room DROP COLUMN IF EXISTS "anonymous";
+ALTER TABLE question DROP COLUMN IF EXISTS "author_name";
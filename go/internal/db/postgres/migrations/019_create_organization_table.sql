@@ -0,0 +1,63 @@
+-- Rooms belong to an organization, so several teams can share a deployment
+-- without seeing each other's rooms. Organizations manage their rooms from
+-- their own servers with API keys, of which only a hash is stored.
+CREATE TABLE IF NOT EXISTS organization (
+    "id"         uuid PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "name"       VARCHAR(255)     NOT NULL,
+    "slug"       TEXT             NOT NULL UNIQUE,
+    "created_at" TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+-- The default organization takes the existing rooms, and every request that
+-- doesn't name an organization acts within it.
+INSERT INTO organization ("id", "name", "slug")
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE room ADD COLUMN IF NOT EXISTS "organization_id" uuid;
+
+UPDATE room
+SET "organization_id" = '00000000-0000-0000-0000-000000000001'
+WHERE "organization_id" IS NULL;
+
+ALTER TABLE room
+    ALTER COLUMN "organization_id" SET NOT NULL,
+    ADD CONSTRAINT room_organization_id_fkey
+        FOREIGN KEY ("organization_id") REFERENCES organization (id) ON DELETE CASCADE;
+
+-- Slugs only need to be unique within an organization.
+DROP INDEX IF EXISTS room_slug_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS room_slug_idx ON room ("organization_id", "slug");
+
+-- Listings are per organization now.
+DROP INDEX IF EXISTS room_created_at_id_idx;
+CREATE INDEX IF NOT EXISTS room_organization_created_at_id_idx ON room ("organization_id", "created_at" DESC, "id" DESC) WHERE "deleted_at" IS NULL;
+
+CREATE TABLE IF NOT EXISTS api_key (
+    "id"              uuid PRIMARY KEY NOT NULL DEFAULT gen_random_uuid(),
+    "organization_id" uuid             NOT NULL,
+    "name"            VARCHAR(255)     NOT NULL,
+    "key_hash"        BYTEA            NOT NULL UNIQUE,
+    "created_at"      TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+
+    FOREIGN KEY (organization_id) REFERENCES organization (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS api_key_organization_id_idx ON api_key ("organization_id");
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS api_key;
+
+DROP INDEX IF EXISTS room_organization_created_at_id_idx;
+CREATE INDEX IF NOT EXISTS room_created_at_id_idx ON room ("created_at" DESC, "id" DESC) WHERE "deleted_at" IS NULL;
+
+-- Going back fails when two organizations use the same slug.
+DROP INDEX IF EXISTS room_slug_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS room_slug_idx ON room ("slug");
+
+ALTER TABLE room
+    DROP CONSTRAINT IF EXISTS room_organization_id_fkey,
+    DROP COLUMN IF EXISTS "organization_id";
+
+DROP TABLE IF EXISTS organization;
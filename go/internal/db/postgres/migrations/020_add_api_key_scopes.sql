@@ -0,0 +1,15 @@
+-- Keys created before scopes existed keep managing every room of their
+-- organization; new ones are read-only unless they're given more.
+ALTER TABLE api_key
+    ADD COLUMN IF NOT EXISTS "scopes"       TEXT[]      NOT NULL DEFAULT '{rooms:write,questions:moderate}',
+    ADD COLUMN IF NOT EXISTS "last_used_at" TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS "revoked_at"   TIMESTAMPTZ;
+
+ALTER TABLE api_key ALTER COLUMN "scopes" SET DEFAULT '{read-only}';
+
+---- create above / drop below ----
+
+ALTER TABLE api_key
+    DROP COLUMN IF EXISTS "revoked_at",
+    DROP COLUMN IF EXISTS "last_used_at",
+    DROP COLUMN IF EXISTS "scopes";
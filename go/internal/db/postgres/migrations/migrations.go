@@ -0,0 +1,101 @@
+// Package migrations embeds the SQL migrations of the project and runs them
+// with tern's migration library, so the ama binary can manage the schema on its
+// own. Files keep tern's "---- create above / drop below ----" format and the
+// version is tracked in the same table the tern CLI uses.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/tern/v2/migrate"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// VersionTable is where the current schema version is stored.
+const VersionTable = "public.schema_version"
+
+type Migrator struct {
+	migrator *migrate.Migrator
+}
+
+type Migration struct {
+	Version int32
+	Name    string
+	Applied bool
+}
+
+type Status struct {
+	Current    int32
+	Migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations, creating the version table when
+// it doesn't exist yet. Every migration step is logged as it starts.
+func NewMigrator(ctx context.Context, conn *pgx.Conn) (*Migrator, error) {
+	migrator, err := migrate.NewMigrator(ctx, conn, VersionTable)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrator.LoadMigrations(files); err != nil {
+		return nil, err
+	}
+
+	migrator.OnStart = func(sequence int32, name, direction, _ string) {
+		slog.Info("Running migration", "version", sequence, "name", name, "direction", direction)
+	}
+
+	return &Migrator{migrator: migrator}, nil
+}
+
+// Up applies every pending migration.
+func (migrator *Migrator) Up(ctx context.Context) error {
+	return migrator.migrator.Migrate(ctx)
+}
+
+// Down reverts the last applied migration.
+func (migrator *Migrator) Down(ctx context.Context) error {
+	current, err := migrator.migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current == 0 {
+		return fmt.Errorf("no migration to revert")
+	}
+
+	return migrator.migrator.MigrateTo(ctx, current-1)
+}
+
+// To migrates up or down until the schema is at the given version.
+func (migrator *Migrator) To(ctx context.Context, version int32) error {
+	if version < 0 || int(version) > len(migrator.migrator.Migrations) {
+		return fmt.Errorf("version %d is out of range, expected 0 to %d", version, len(migrator.migrator.Migrations))
+	}
+
+	return migrator.migrator.MigrateTo(ctx, version)
+}
+
+func (migrator *Migrator) Status(ctx context.Context) (Status, error) {
+	current, err := migrator.migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	status := Status{Current: current}
+	for _, migration := range migrator.migrator.Migrations {
+		status.Migrations = append(status.Migrations, Migration{
+			Version: migration.Sequence,
+			Name:    migration.Name,
+			Applied: migration.Sequence <= current,
+		})
+	}
+
+	return status, nil
+}
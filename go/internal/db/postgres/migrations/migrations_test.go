@@ -0,0 +1,62 @@
+package migrations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres/migrations"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres/postgrestest"
+)
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := postgrestest.Start(ctx)
+	if err != nil {
+		t.Skip("postgres is not available:", err)
+	}
+	t.Cleanup(server.Stop)
+
+	pool := server.NewDatabase(t)
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+
+	migrator, err := migrations.NewMigrator(ctx, conn.Conn())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := int32(len(status.Migrations))
+	if status.Current != latest {
+		t.Fatalf("expected the test database at version %d, got %d", latest, status.Current)
+	}
+
+	if err := migrator.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.To(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err = migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Current != latest {
+		t.Fatalf("expected version %d after migrating up again, got %d", latest, status.Current)
+	}
+
+	if err := migrator.To(ctx, latest+1); err == nil {
+		t.Fatal("expected an error for a version past the last migration")
+	}
+}
@@ -9,19 +9,117 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Question struct {
-	ID            uuid.UUID
-	RoomID        uuid.UUID
-	Text          string
-	ReactionCount int64
-	Answered      bool
-	CreatedAt     pgtype.Timestamp
-	UpdatedAt     pgtype.Timestamp
+type ApiKey struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+	Name           string
+	KeyHash        []byte
+	CreatedAt      pgtype.Timestamptz
+	Scopes         []string
+	LastUsedAt     pgtype.Timestamptz
+	RevokedAt      pgtype.Timestamptz
 }
 
-type Room struct {
+type AuditLog struct {
+	ID        int64
+	RoomID    uuid.UUID
+	Entity    string
+	EntityID  uuid.UUID
+	Action    string
+	Actor     string
+	Changes   []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+type Comment struct {
+	ID         uuid.UUID
+	QuestionID uuid.UUID
+	Text       string
+	AuthorHash []byte
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Organization struct {
 	ID        uuid.UUID
 	Name      string
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+	Slug      string
+	CreatedAt pgtype.Timestamptz
+}
+
+type Poll struct {
+	ID        uuid.UUID
+	RoomID    uuid.UUID
+	Question  string
+	CreatedAt pgtype.Timestamptz
+	ClosedAt  pgtype.Timestamptz
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	PollID   uuid.UUID
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	PollID          uuid.UUID
+	ParticipantHash []byte
+	OptionID        uuid.UUID
+	CreatedAt       pgtype.Timestamptz
+}
+
+type Question struct {
+	ID               uuid.UUID
+	RoomID           uuid.UUID
+	Text             string
+	ReactionCount    int64
+	Answered         bool
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+	DeletedAt        pgtype.Timestamptz
+	AuthorHash       []byte
+	DownvoteCount    int64
+	SpotlightSeconds float64
+	PinnedAt         pgtype.Timestamptz
+	PinPosition      pgtype.Int4
+	AuthorName       pgtype.Text
+}
+
+type QuestionEdit struct {
+	ID         uuid.UUID
+	QuestionID uuid.UUID
+	Text       string
+	EditedAt   pgtype.Timestamptz
+}
+
+type QuestionReaction struct {
+	QuestionID uuid.UUID
+	Kind       string
+	Count      int64
+}
+
+type Room struct {
+	ID                  uuid.UUID
+	Name                string
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+	ClosedAt            pgtype.Timestamptz
+	OwnerTokenHash      []byte
+	DeletedAt           pgtype.Timestamptz
+	Visibility          string
+	JoinCode            pgtype.Text
+	Slug                string
+	ReactionKinds       []string
+	PrimaryReaction     string
+	Ranking             string
+	SpotlightQuestionID pgtype.UUID
+	SpotlightedAt       pgtype.Timestamptz
+	Anonymous           bool
+	OrganizationID      uuid.UUID
+}
+
+type RoomGrant struct {
+	RoomID          uuid.UUID
+	ParticipantHash []byte
+	CreatedAt       pgtype.Timestamptz
 }
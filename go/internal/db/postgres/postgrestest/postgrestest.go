@@ -0,0 +1,212 @@
+// Package postgrestest runs a throwaway PostgreSQL server for integration
+// tests. It initializes a cluster in a temporary directory with the initdb and
+// pg_ctl binaries found through PG_BIN, the PATH or the usual Debian install
+// location, applies the migrations once to a template database and hands every
+// test its own copy of it.
+package postgrestest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres/migrations"
+)
+
+const (
+	user             = "postgres"
+	templateDatabase = "ama_template"
+)
+
+// ErrUnavailable is returned by Start when no PostgreSQL binaries can be found.
+var ErrUnavailable = errors.New("postgrestest: initdb and pg_ctl not found, set PG_BIN or add them to the PATH")
+
+type Server struct {
+	binDir    string
+	dataDir   string
+	socketDir string
+	port      int
+	databases atomic.Int64
+}
+
+// Start initializes and boots a new cluster, then creates the migrated
+// template database every test database is cloned from.
+func Start(ctx context.Context) (*Server, error) {
+	binDir, err := findBinDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir, err := os.MkdirTemp("", "ama-postgres-data-")
+	if err != nil {
+		return nil, err
+	}
+
+	// Unix socket paths are limited to ~100 bytes, so the socket gets its own
+	// short directory instead of living inside the data directory.
+	socketDir, err := os.MkdirTemp("", "ama-pg-")
+	if err != nil {
+		_ = os.RemoveAll(dataDir)
+		return nil, err
+	}
+
+	port, err := freePort()
+	if err != nil {
+		_ = os.RemoveAll(dataDir)
+		_ = os.RemoveAll(socketDir)
+		return nil, err
+	}
+
+	server := &Server{binDir: binDir, dataDir: dataDir, socketDir: socketDir, port: port}
+
+	if err := server.run("initdb", "--pgdata", dataDir, "--username", user, "--auth", "trust", "--encoding", "UTF8", "--no-sync"); err != nil {
+		server.cleanup()
+		return nil, err
+	}
+
+	options := fmt.Sprintf(
+		"-p %d -c listen_addresses=127.0.0.1 -c unix_socket_directories=%s -c fsync=off -c synchronous_commit=off -c full_page_writes=off",
+		port,
+		socketDir,
+	)
+	if err := server.run("pg_ctl", "start", "--wait", "--pgdata", dataDir, "--log", filepath.Join(dataDir, "server.log"), "-o", options); err != nil {
+		server.cleanup()
+		return nil, err
+	}
+
+	if err := server.createTemplate(ctx); err != nil {
+		server.Stop()
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// Stop shuts the cluster down and removes its files.
+func (server *Server) Stop() {
+	_ = server.run("pg_ctl", "stop", "--pgdata", server.dataDir, "--mode", "immediate", "--wait")
+	server.cleanup()
+}
+
+// ConnString returns the connection string for the named database.
+func (server *Server) ConnString(database string) string {
+	return fmt.Sprintf("host=127.0.0.1 port=%d user=%s dbname=%s sslmode=disable", server.port, user, database)
+}
+
+// NewDatabase creates a migrated database private to the test and returns a
+// pool connected to it. Both are discarded when the test finishes.
+func (server *Server) NewDatabase(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+
+	ctx := context.Background()
+	name := fmt.Sprintf("ama_test_%d", server.databases.Add(1))
+
+	if err := server.exec(ctx, fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", name, templateDatabase)); err != nil {
+		t.Fatalf("postgrestest: create database: %v", err)
+	}
+
+	pool, err := pgxpool.New(ctx, server.ConnString(name))
+	if err != nil {
+		t.Fatalf("postgrestest: connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+
+		if err := server.exec(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", name)); err != nil {
+			t.Logf("postgrestest: drop database: %v", err)
+		}
+	})
+
+	return pool
+}
+
+func (server *Server) createTemplate(ctx context.Context) error {
+	if err := server.exec(ctx, "CREATE DATABASE "+templateDatabase); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, server.ConnString(templateDatabase))
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	migrator, err := migrations.NewMigrator(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up(ctx)
+}
+
+// exec runs a statement on the maintenance database, which is needed for
+// CREATE DATABASE and DROP DATABASE.
+func (server *Server) exec(ctx context.Context, sql string) error {
+	conn, err := pgx.Connect(ctx, server.ConnString("postgres"))
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close(ctx)
+
+	_, err = conn.Exec(ctx, sql)
+	return err
+}
+
+func (server *Server) run(name string, args ...string) error {
+	output, err := exec.Command(filepath.Join(server.binDir, name), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("postgrestest: %s: %w\n%s", name, err, output)
+	}
+
+	return nil
+}
+
+func (server *Server) cleanup() {
+	_ = os.RemoveAll(server.dataDir)
+	_ = os.RemoveAll(server.socketDir)
+}
+
+func findBinDir() (string, error) {
+	if dir := os.Getenv("PG_BIN"); dir != "" {
+		return dir, nil
+	}
+
+	if path, err := exec.LookPath("pg_ctl"); err == nil {
+		return filepath.Dir(path), nil
+	}
+
+	candidates, _ := filepath.Glob("/usr/lib/postgresql/*/bin/pg_ctl")
+	if len(candidates) > 0 {
+		sort.Strings(candidates)
+		return filepath.Dir(candidates[len(candidates)-1]), nil
+	}
+
+	return "", ErrUnavailable
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -9,22 +9,257 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addQuestionReaction = `-- name: AddQuestionReaction :one
+INSERT INTO question_reaction
+  ("question_id", "kind", "count")
+  VALUES ($1, $2, 1)
+ON CONFLICT ("question_id", "kind") DO UPDATE
+SET "count" = question_reaction."count" + 1
+RETURNING "count"
+`
+
+type AddQuestionReactionParams struct {
+	QuestionID uuid.UUID
+	Kind       string
+}
+
+func (q *Queries) AddQuestionReaction(ctx context.Context, arg AddQuestionReactionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addQuestionReaction, arg.QuestionID, arg.Kind)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const addSpotlightTime = `-- name: AddSpotlightTime :exec
+UPDATE question q
+SET
+    "spotlight_seconds" = q."spotlight_seconds" + GREATEST(EXTRACT(EPOCH FROM NOW() - r."spotlighted_at"), 0)::float8
+FROM room r
+WHERE r."id" = $1 AND r."organization_id" = $2 AND q."id" = r."spotlight_question_id"
+`
+
+type AddSpotlightTimeParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) AddSpotlightTime(ctx context.Context, arg AddSpotlightTimeParams) error {
+	_, err := q.db.Exec(ctx, addSpotlightTime, arg.ID, arg.OrganizationID)
+	return err
+}
+
+const closePoll = `-- name: ClosePoll :one
+UPDATE poll
+SET
+    "closed_at" = COALESCE("closed_at", NOW())
+WHERE "id" = $1
+RETURNING "id", "room_id", "question", "created_at", "closed_at"
+`
+
+func (q *Queries) ClosePoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRow(ctx, closePoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const closeRoom = `-- name: CloseRoom :one
+UPDATE room
+SET
+    "closed_at" = COALESCE("closed_at", NOW())
+WHERE "id" = $1 AND "organization_id" = $2
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+`
+
+type CloseRoomParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) CloseRoom(ctx context.Context, arg CloseRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, closeRoom, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const countQuestionComments = `-- name: CountQuestionComments :one
+SELECT COUNT(*) AS "comment_count"
+FROM comment
+WHERE "question_id" = $1
+`
+
+func (q *Queries) CountQuestionComments(ctx context.Context, questionID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countQuestionComments, questionID)
+	var comment_count int64
+	err := row.Scan(&comment_count)
+	return comment_count, err
+}
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_key
+  ("organization_id", "name", "key_hash", "scopes")
+  VALUES ($1, $2, $3, $4)
+RETURNING "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at"
+`
+
+type CreateAPIKeyParams struct {
+	OrganizationID uuid.UUID
+	Name           string
+	KeyHash        []byte
+	Scopes         []string
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, createAPIKey, arg.OrganizationID, arg.Name, arg.KeyHash, arg.Scopes)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Name,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.Scopes,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const createComment = `-- name: CreateComment :one
+INSERT INTO comment
+  ("question_id", "text", "author_hash")
+  VALUES ($1, $2, $3)
+RETURNING "id", "question_id", "text", "author_hash", "created_at"
+`
+
+type CreateCommentParams struct {
+	QuestionID uuid.UUID
+	Text       string
+	AuthorHash []byte
+}
+
+func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	row := q.db.QueryRow(ctx, createComment, arg.QuestionID, arg.Text, arg.AuthorHash)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.QuestionID,
+		&i.Text,
+		&i.AuthorHash,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOrganization = `-- name: CreateOrganization :one
+INSERT INTO organization
+  ("name", "slug")
+  VALUES ($1, $2)
+RETURNING "id", "name", "slug", "created_at"
+`
+
+type CreateOrganizationParams struct {
+	Name string
+	Slug string
+}
+
+func (q *Queries) CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error) {
+	row := q.db.QueryRow(ctx, createOrganization, arg.Name, arg.Slug)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPoll = `-- name: CreatePoll :one
+INSERT INTO poll
+  ("room_id", "question")
+  VALUES ($1, $2)
+RETURNING "id", "room_id", "question", "created_at", "closed_at"
+`
+
+type CreatePollParams struct {
+	RoomID   uuid.UUID
+	Question string
+}
+
+func (q *Queries) CreatePoll(ctx context.Context, arg CreatePollParams) (Poll, error) {
+	row := q.db.QueryRow(ctx, createPoll, arg.RoomID, arg.Question)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const createPollOptions = `-- name: CreatePollOptions :exec
+INSERT INTO poll_option ("poll_id", "position", "text")
+SELECT $1::uuid, options."position", options."text"
+FROM unnest($2::text[]) WITH ORDINALITY AS options("text", "position")
+`
+
+type CreatePollOptionsParams struct {
+	PollID uuid.UUID
+	Texts  []string
+}
+
+func (q *Queries) CreatePollOptions(ctx context.Context, arg CreatePollOptionsParams) error {
+	_, err := q.db.Exec(ctx, createPollOptions, arg.PollID, arg.Texts)
+	return err
+}
+
 const createQuestion = `-- name: CreateQuestion :one
 INSERT INTO question 
-  ("room_id", "text")
-  VALUES ($1, $2)
-RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at"
+  ("room_id", "text", "author_hash", "author_name")
+  VALUES ($1, $2, $3, $4)
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
 `
 
 type CreateQuestionParams struct {
-	RoomID uuid.UUID
-	Text   string
+	RoomID     uuid.UUID
+	Text       string
+	AuthorHash []byte
+	AuthorName pgtype.Text
 }
 
 func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
-	row := q.db.QueryRow(ctx, createQuestion, arg.RoomID, arg.Text)
+	row := q.db.QueryRow(ctx, createQuestion, arg.RoomID, arg.Text, arg.AuthorHash, arg.AuthorName)
 	var i Question
 	err := row.Scan(
 		&i.ID,
@@ -34,94 +269,281 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.Answered,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthorHash,
+		&i.DownvoteCount,
+		&i.SpotlightSeconds,
+		&i.PinnedAt,
+		&i.PinPosition,
+		&i.AuthorName,
 	)
 	return i, err
 }
 
+const createQuestionEdit = `-- name: CreateQuestionEdit :exec
+INSERT INTO question_edit
+  ("question_id", "text")
+  VALUES ($1, $2)
+`
+
+type CreateQuestionEditParams struct {
+	QuestionID uuid.UUID
+	Text       string
+}
+
+func (q *Queries) CreateQuestionEdit(ctx context.Context, arg CreateQuestionEditParams) error {
+	_, err := q.db.Exec(ctx, createQuestionEdit, arg.QuestionID, arg.Text)
+	return err
+}
+
+const createQuestionReactionsFromCounts = `-- name: CreateQuestionReactionsFromCounts :exec
+INSERT INTO question_reaction ("question_id", "kind", "count")
+SELECT "id", $1::text, "reaction_count"
+FROM question
+WHERE "room_id" = $2 AND "reaction_count" > 0
+ON CONFLICT DO NOTHING
+`
+
+type CreateQuestionReactionsFromCountsParams struct {
+	Kind   string
+	RoomID uuid.UUID
+}
+
+func (q *Queries) CreateQuestionReactionsFromCounts(ctx context.Context, arg CreateQuestionReactionsFromCountsParams) error {
+	_, err := q.db.Exec(ctx, createQuestionReactionsFromCounts, arg.Kind, arg.RoomID)
+	return err
+}
+
+type CreateQuestionsParams struct {
+	ID            uuid.UUID
+	RoomID        uuid.UUID
+	Text          string
+	ReactionCount int64
+	Answered      bool
+}
+
 const createRoom = `-- name: CreateRoom :one
 INSERT INTO room 
-  ("name") VALUES
-  ($1)
-RETURNING "id", "name", "created_at", "updated_at"
+  ("name", "owner_token_hash", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "anonymous", "organization_id") VALUES
+  ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
 `
 
-func (q *Queries) CreateRoom(ctx context.Context, name string) (Room, error) {
-	row := q.db.QueryRow(ctx, createRoom, name)
+type CreateRoomParams struct {
+	Name            string
+	OwnerTokenHash  []byte
+	Visibility      string
+	JoinCode        pgtype.Text
+	Slug            string
+	ReactionKinds   []string
+	PrimaryReaction string
+	Ranking         string
+	Anonymous       bool
+	OrganizationID  uuid.UUID
+}
+
+func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, createRoom, arg.Name, arg.OwnerTokenHash, arg.Visibility, arg.JoinCode, arg.Slug, arg.ReactionKinds, arg.PrimaryReaction, arg.Ranking, arg.Anonymous, arg.OrganizationID)
 	var i Room
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
-const getQuestion = `-- name: GetQuestion :one
+const createRoomGrant = `-- name: CreateRoomGrant :exec
+INSERT INTO room_grant
+  ("room_id", "participant_hash")
+  VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type CreateRoomGrantParams struct {
+	RoomID          uuid.UUID
+	ParticipantHash []byte
+}
+
+func (q *Queries) CreateRoomGrant(ctx context.Context, arg CreateRoomGrantParams) error {
+	_, err := q.db.Exec(ctx, createRoomGrant, arg.RoomID, arg.ParticipantHash)
+	return err
+}
+
+const deleteComment = `-- name: DeleteComment :execrows
+DELETE FROM comment
+WHERE "id" = $1
+`
+
+func (q *Queries) DeleteComment(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteComment, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteQuestion = `-- name: DeleteQuestion :execrows
+DELETE FROM question
+WHERE "id" = $1
+`
+
+func (q *Queries) DeleteQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteQuestion, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRoom = `-- name: DeleteRoom :execrows
+DELETE FROM room
+WHERE "id" = $1 AND "organization_id" = $2
+`
+
+type DeleteRoomParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) DeleteRoom(ctx context.Context, arg DeleteRoomParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRoom, arg.ID, arg.OrganizationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRoomQuestions = `-- name: DeleteRoomQuestions :execrows
+DELETE FROM question
+WHERE "room_id" = $1
+`
+
+func (q *Queries) DeleteRoomQuestions(ctx context.Context, roomID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRoomQuestions, roomID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const downvoteQuestion = `-- name: DownvoteQuestion :one
+UPDATE question
+SET
+    "downvote_count" = "downvote_count" + 1
+WHERE "id" = $1
+RETURNING "downvote_count"
+`
+
+func (q *Queries) DownvoteQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, downvoteQuestion, id)
+	var downvote_count int64
+	err := row.Scan(&downvote_count)
+	return downvote_count, err
+}
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
 SELECT
-    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at"
-FROM question
+    "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at"
+FROM api_key
+WHERE "key_hash" = $1 AND "revoked_at" IS NULL
+`
+
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Name,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.Scopes,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getComment = `-- name: GetComment :one
+SELECT
+    "id", "question_id", "text", "author_hash", "created_at"
+FROM comment
 WHERE "id" = $1
 `
 
-func (q *Queries) GetQuestion(ctx context.Context, id uuid.UUID) (Question, error) {
-	row := q.db.QueryRow(ctx, getQuestion, id)
-	var i Question
+func (q *Queries) GetComment(ctx context.Context, id uuid.UUID) (Comment, error) {
+	row := q.db.QueryRow(ctx, getComment, id)
+	var i Comment
 	err := row.Scan(
 		&i.ID,
-		&i.RoomID,
+		&i.QuestionID,
 		&i.Text,
-		&i.ReactionCount,
-		&i.Answered,
+		&i.AuthorHash,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getRoom = `-- name: GetRoom :one
-SELECT 
-    "id", "name", "created_at", "updated_at"
-FROM room
+const getOrganization = `-- name: GetOrganization :one
+SELECT
+    "id", "name", "slug", "created_at"
+FROM organization
 WHERE "id" = $1
 `
 
-func (q *Queries) GetRoom(ctx context.Context, id uuid.UUID) (Room, error) {
-	row := q.db.QueryRow(ctx, getRoom, id)
-	var i Room
+func (q *Queries) GetOrganization(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRow(ctx, getOrganization, id)
+	var i Organization
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
+		&i.Slug,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getRoomQuestions = `-- name: GetRoomQuestions :many
+const getOrganizationAPIKeys = `-- name: GetOrganizationAPIKeys :many
 SELECT
-    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at"
-FROM question
-WHERE "room_id" = $1
+    "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at"
+FROM api_key
+WHERE "organization_id" = $1
+ORDER BY "created_at"
 `
 
-func (q *Queries) GetRoomQuestions(ctx context.Context, roomID uuid.UUID) ([]Question, error) {
-	rows, err := q.db.Query(ctx, getRoomQuestions, roomID)
+func (q *Queries) GetOrganizationAPIKeys(ctx context.Context, organizationID uuid.UUID) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, getOrganizationAPIKeys, organizationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Question
+	var items []ApiKey
 	for rows.Next() {
-		var i Question
+		var i ApiKey
 		if err := rows.Scan(
 			&i.ID,
-			&i.RoomID,
-			&i.Text,
-			&i.ReactionCount,
-			&i.Answered,
+			&i.OrganizationID,
+			&i.Name,
+			&i.KeyHash,
 			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.Scopes,
+			&i.LastUsedAt,
+			&i.RevokedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -133,26 +555,46 @@ func (q *Queries) GetRoomQuestions(ctx context.Context, roomID uuid.UUID) ([]Que
 	return items, nil
 }
 
-const getRooms = `-- name: GetRooms :many
-SELECT 
-    "id", "name", "created_at", "updated_at"
-FROM room
+const getOrganizationBySlug = `-- name: GetOrganizationBySlug :one
+SELECT
+    "id", "name", "slug", "created_at"
+FROM organization
+WHERE "slug" = $1
+`
+
+func (q *Queries) GetOrganizationBySlug(ctx context.Context, slug string) (Organization, error) {
+	row := q.db.QueryRow(ctx, getOrganizationBySlug, slug)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOrganizations = `-- name: GetOrganizations :many
+SELECT
+    "id", "name", "slug", "created_at"
+FROM organization
+ORDER BY "created_at"
 `
 
-func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
-	rows, err := q.db.Query(ctx, getRooms)
+func (q *Queries) GetOrganizations(ctx context.Context) ([]Organization, error) {
+	rows, err := q.db.Query(ctx, getOrganizations)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Room
+	var items []Organization
 	for rows.Next() {
-		var i Room
+		var i Organization
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
+			&i.Slug,
 			&i.CreatedAt,
-			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -164,37 +606,1015 @@ func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
 	return items, nil
 }
 
-const markQuestionAsAnswered = `-- name: MarkQuestionAsAnswered :exec
-UPDATE question
-SET
-    "answered" = true
+const getPoll = `-- name: GetPoll :one
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM poll
 WHERE "id" = $1
 `
 
-func (q *Queries) MarkQuestionAsAnswered(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, markQuestionAsAnswered, id)
-	return err
+func (q *Queries) GetPoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRow(ctx, getPoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
 }
 
-const reactToQuestion = `-- name: ReactToQuestion :one
-UPDATE question
-SET
-    "reaction_count" = "reaction_count" + 1
-WHERE "id" = $1
-RETURNING "reaction_count"
+const getPollOptions = `-- name: GetPollOptions :many
+SELECT
+    o."id", o."poll_id", o."position", o."text", COUNT(v."option_id") AS "vote_count"
+FROM poll_option o
+LEFT JOIN poll_vote v ON v."option_id" = o."id"
+WHERE o."poll_id" = $1
+GROUP BY o."id"
+ORDER BY o."position"
 `
 
-func (q *Queries) ReactToQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
-	row := q.db.QueryRow(ctx, reactToQuestion, id)
-	var reaction_count int64
-	err := row.Scan(&reaction_count)
-	return reaction_count, err
+type GetPollOptionsRow struct {
+	ID        uuid.UUID
+	PollID    uuid.UUID
+	Position  int32
+	Text      string
+	VoteCount int64
 }
 
-const removeReactionFromQuestion = `-- name: RemoveReactionFromQuestion :one
-UPDATE question
-SET
-    "reaction_count" = "reaction_count" - 1
+func (q *Queries) GetPollOptions(ctx context.Context, pollID uuid.UUID) ([]GetPollOptionsRow, error) {
+	rows, err := q.db.Query(ctx, getPollOptions, pollID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPollOptionsRow
+	for rows.Next() {
+		var i GetPollOptionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Text,
+			&i.VoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuestion = `-- name: GetQuestion :one
+SELECT
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+FROM question
+WHERE "id" = $1 AND "deleted_at" IS NULL
+`
+
+func (q *Queries) GetQuestion(ctx context.Context, id uuid.UUID) (Question, error) {
+	row := q.db.QueryRow(ctx, getQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Text,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthorHash,
+		&i.DownvoteCount,
+		&i.SpotlightSeconds,
+		&i.PinnedAt,
+		&i.PinPosition,
+		&i.AuthorName,
+	)
+	return i, err
+}
+
+const getQuestionComments = `-- name: GetQuestionComments :many
+SELECT
+    "id", "question_id", "text", "author_hash", "created_at"
+FROM comment
+WHERE "question_id" = $1
+ORDER BY "created_at", "id"
+`
+
+func (q *Queries) GetQuestionComments(ctx context.Context, questionID uuid.UUID) ([]Comment, error) {
+	rows, err := q.db.Query(ctx, getQuestionComments, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuestionID,
+			&i.Text,
+			&i.AuthorHash,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuestionEdits = `-- name: GetQuestionEdits :many
+SELECT
+    "id", "question_id", "text", "edited_at"
+FROM question_edit
+WHERE "question_id" = $1
+ORDER BY "edited_at", "id"
+`
+
+func (q *Queries) GetQuestionEdits(ctx context.Context, questionID uuid.UUID) ([]QuestionEdit, error) {
+	rows, err := q.db.Query(ctx, getQuestionEdits, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuestionEdit
+	for rows.Next() {
+		var i QuestionEdit
+		if err := rows.Scan(
+			&i.ID,
+			&i.QuestionID,
+			&i.Text,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuestionReactions = `-- name: GetQuestionReactions :many
+SELECT
+    "question_id", "kind", "count"
+FROM question_reaction
+WHERE "question_id" = $1
+`
+
+func (q *Queries) GetQuestionReactions(ctx context.Context, questionID uuid.UUID) ([]QuestionReaction, error) {
+	rows, err := q.db.Query(ctx, getQuestionReactions, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuestionReaction
+	for rows.Next() {
+		var i QuestionReaction
+		if err := rows.Scan(
+			&i.QuestionID,
+			&i.Kind,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRankedRoomQuestions = `-- name: GetRankedRoomQuestions :many
+SELECT
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+FROM question
+WHERE "room_id" = $1 AND "deleted_at" IS NULL
+ORDER BY
+    "pin_position" NULLS LAST,
+    CASE $2::text
+        WHEN 'wilson' THEN question_wilson_score("reaction_count", "downvote_count")
+        WHEN 'hot' THEN question_hot_score("reaction_count", "downvote_count", "created_at", NOW())
+        ELSE ("reaction_count" - "downvote_count")::float8
+    END DESC,
+    "created_at", "id"
+LIMIT $3 OFFSET $4
+`
+
+type GetRankedRoomQuestionsParams struct {
+	RoomID     uuid.UUID
+	Ranking    string
+	PageSize   pgtype.Int4
+	PageOffset int32
+}
+
+func (q *Queries) GetRankedRoomQuestions(ctx context.Context, arg GetRankedRoomQuestionsParams) ([]Question, error) {
+	rows, err := q.db.Query(ctx, getRankedRoomQuestions, arg.RoomID, arg.Ranking, arg.PageSize, arg.PageOffset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Question
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Text,
+			&i.ReactionCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.AuthorHash,
+			&i.DownvoteCount,
+			&i.SpotlightSeconds,
+			&i.PinnedAt,
+			&i.PinPosition,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoom = `-- name: GetRoom :one
+SELECT 
+    "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+FROM room
+WHERE "id" = $1 AND "organization_id" = $2 AND "deleted_at" IS NULL
+`
+
+type GetRoomParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) GetRoom(ctx context.Context, arg GetRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, getRoom, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getRoomAuditLog = `-- name: GetRoomAuditLog :many
+SELECT
+    "id", "room_id", "entity", "entity_id", "action", "actor", "changes", "created_at"
+FROM audit_log
+WHERE "room_id" = $1
+ORDER BY "id"
+`
+
+func (q *Queries) GetRoomAuditLog(ctx context.Context, roomID uuid.UUID) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, getRoomAuditLog, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Entity,
+			&i.EntityID,
+			&i.Action,
+			&i.Actor,
+			&i.Changes,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomBySlug = `-- name: GetRoomBySlug :one
+SELECT 
+    "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+FROM room
+WHERE "slug" = $1 AND "organization_id" = $2 AND "deleted_at" IS NULL
+`
+
+type GetRoomBySlugParams struct {
+	Slug           string
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) GetRoomBySlug(ctx context.Context, arg GetRoomBySlugParams) (Room, error) {
+	row := q.db.QueryRow(ctx, getRoomBySlug, arg.Slug, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getRoomCommentCounts = `-- name: GetRoomCommentCounts :many
+SELECT
+    c."question_id", COUNT(*) AS "comment_count"
+FROM comment c
+JOIN question q ON q."id" = c."question_id"
+WHERE q."room_id" = $1 AND q."deleted_at" IS NULL
+GROUP BY c."question_id"
+`
+
+type GetRoomCommentCountsRow struct {
+	QuestionID   uuid.UUID
+	CommentCount int64
+}
+
+func (q *Queries) GetRoomCommentCounts(ctx context.Context, roomID uuid.UUID) ([]GetRoomCommentCountsRow, error) {
+	rows, err := q.db.Query(ctx, getRoomCommentCounts, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomCommentCountsRow
+	for rows.Next() {
+		var i GetRoomCommentCountsRow
+		if err := rows.Scan(
+			&i.QuestionID,
+			&i.CommentCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPinnedQuestionIDs = `-- name: GetRoomPinnedQuestionIDs :many
+SELECT "id"
+FROM question
+WHERE "room_id" = $1 AND "pinned_at" IS NOT NULL AND "deleted_at" IS NULL
+ORDER BY "pin_position"
+`
+
+func (q *Queries) GetRoomPinnedQuestionIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, getRoomPinnedQuestionIDs, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPollOptions = `-- name: GetRoomPollOptions :many
+SELECT
+    o."id", o."poll_id", o."position", o."text", COUNT(v."option_id") AS "vote_count"
+FROM poll_option o
+JOIN poll p ON p."id" = o."poll_id"
+LEFT JOIN poll_vote v ON v."option_id" = o."id"
+WHERE p."room_id" = $1
+GROUP BY o."id"
+ORDER BY o."poll_id", o."position"
+`
+
+type GetRoomPollOptionsRow struct {
+	ID        uuid.UUID
+	PollID    uuid.UUID
+	Position  int32
+	Text      string
+	VoteCount int64
+}
+
+func (q *Queries) GetRoomPollOptions(ctx context.Context, roomID uuid.UUID) ([]GetRoomPollOptionsRow, error) {
+	rows, err := q.db.Query(ctx, getRoomPollOptions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomPollOptionsRow
+	for rows.Next() {
+		var i GetRoomPollOptionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PollID,
+			&i.Position,
+			&i.Text,
+			&i.VoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomPolls = `-- name: GetRoomPolls :many
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM poll
+WHERE "room_id" = $1
+ORDER BY "created_at", "id"
+`
+
+func (q *Queries) GetRoomPolls(ctx context.Context, roomID uuid.UUID) ([]Poll, error) {
+	rows, err := q.db.Query(ctx, getRoomPolls, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Question,
+			&i.CreatedAt,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomQuestionReactions = `-- name: GetRoomQuestionReactions :many
+SELECT
+    r."question_id", r."kind", r."count"
+FROM question_reaction r
+JOIN question q ON q."id" = r."question_id"
+WHERE q."room_id" = $1 AND q."deleted_at" IS NULL
+`
+
+func (q *Queries) GetRoomQuestionReactions(ctx context.Context, roomID uuid.UUID) ([]QuestionReaction, error) {
+	rows, err := q.db.Query(ctx, getRoomQuestionReactions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuestionReaction
+	for rows.Next() {
+		var i QuestionReaction
+		if err := rows.Scan(
+			&i.QuestionID,
+			&i.Kind,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomQuestions = `-- name: GetRoomQuestions :many
+SELECT
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+FROM question
+WHERE "room_id" = $1 AND "deleted_at" IS NULL
+`
+
+func (q *Queries) GetRoomQuestions(ctx context.Context, roomID uuid.UUID) ([]Question, error) {
+	rows, err := q.db.Query(ctx, getRoomQuestions, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Question
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Text,
+			&i.ReactionCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.AuthorHash,
+			&i.DownvoteCount,
+			&i.SpotlightSeconds,
+			&i.PinnedAt,
+			&i.PinPosition,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomQuestionsByPopularity = `-- name: GetRoomQuestionsByPopularity :many
+SELECT
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+FROM question
+WHERE "room_id" = $1 AND "deleted_at" IS NULL
+ORDER BY "reaction_count" DESC, "created_at" ASC
+`
+
+func (q *Queries) GetRoomQuestionsByPopularity(ctx context.Context, roomID uuid.UUID) ([]Question, error) {
+	rows, err := q.db.Query(ctx, getRoomQuestionsByPopularity, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Question
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Text,
+			&i.ReactionCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.AuthorHash,
+			&i.DownvoteCount,
+			&i.SpotlightSeconds,
+			&i.PinnedAt,
+			&i.PinPosition,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRooms = `-- name: GetRooms :many
+SELECT 
+    "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+FROM room
+WHERE "organization_id" = $1 AND "deleted_at" IS NULL
+`
+
+func (q *Queries) GetRooms(ctx context.Context, organizationID uuid.UUID) ([]Room, error) {
+	rows, err := q.db.Query(ctx, getRooms, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+			&i.OwnerTokenHash,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.JoinCode,
+			&i.Slug,
+			&i.ReactionKinds,
+			&i.PrimaryReaction,
+			&i.Ranking,
+			&i.SpotlightQuestionID,
+			&i.SpotlightedAt,
+			&i.Anonymous,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoomsWithQuestionCount = `-- name: GetRoomsWithQuestionCount :many
+SELECT
+    r."id", r."name", r."created_at", r."updated_at", r."closed_at", r."owner_token_hash", r."deleted_at", r."visibility", r."join_code", r."slug", r."reaction_kinds", r."primary_reaction", r."ranking", r."spotlight_question_id", r."spotlighted_at", r."anonymous", r."organization_id",
+    COUNT(q."id") AS "question_count"
+FROM room r
+LEFT JOIN question q ON q."room_id" = r."id" AND q."deleted_at" IS NULL
+WHERE r."organization_id" = $1
+  AND ($2::boolean OR r."deleted_at" IS NULL)
+GROUP BY r."id"
+ORDER BY r."created_at"
+`
+
+type GetRoomsWithQuestionCountParams struct {
+	OrganizationID uuid.UUID
+	IncludeDeleted bool
+}
+
+type GetRoomsWithQuestionCountRow struct {
+	ID                  uuid.UUID
+	Name                string
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+	ClosedAt            pgtype.Timestamptz
+	OwnerTokenHash      []byte
+	DeletedAt           pgtype.Timestamptz
+	Visibility          string
+	JoinCode            pgtype.Text
+	Slug                string
+	ReactionKinds       []string
+	PrimaryReaction     string
+	Ranking             string
+	SpotlightQuestionID pgtype.UUID
+	SpotlightedAt       pgtype.Timestamptz
+	Anonymous           bool
+	OrganizationID      uuid.UUID
+	QuestionCount       int64
+}
+
+func (q *Queries) GetRoomsWithQuestionCount(ctx context.Context, arg GetRoomsWithQuestionCountParams) ([]GetRoomsWithQuestionCountRow, error) {
+	rows, err := q.db.Query(ctx, getRoomsWithQuestionCount, arg.OrganizationID, arg.IncludeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRoomsWithQuestionCountRow
+	for rows.Next() {
+		var i GetRoomsWithQuestionCountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+			&i.OwnerTokenHash,
+			&i.DeletedAt,
+			&i.Visibility,
+			&i.JoinCode,
+			&i.Slug,
+			&i.ReactionKinds,
+			&i.PrimaryReaction,
+			&i.Ranking,
+			&i.SpotlightQuestionID,
+			&i.SpotlightedAt,
+			&i.Anonymous,
+			&i.OrganizationID,
+			&i.QuestionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hasRoomGrant = `-- name: HasRoomGrant :one
+SELECT EXISTS (
+    SELECT 1 FROM room_grant
+    WHERE "room_id" = $1 AND "participant_hash" = $2
+)
+`
+
+type HasRoomGrantParams struct {
+	RoomID          uuid.UUID
+	ParticipantHash []byte
+}
+
+func (q *Queries) HasRoomGrant(ctx context.Context, arg HasRoomGrantParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasRoomGrant, arg.RoomID, arg.ParticipantHash)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listRoomsByActivity = `-- name: ListRoomsByActivity :many
+SELECT
+    r.id, r.name, r.created_at, r.updated_at, r.closed_at, r.owner_token_hash, r.deleted_at, r.visibility, r.join_code, r.slug, r.reaction_kinds, r.primary_reaction, r.ranking, r.spotlight_question_id, r.spotlighted_at, r.anonymous, r.organization_id,
+    COUNT(q."id") AS "question_count",
+    COUNT(q."id") FILTER (WHERE NOT q."answered") AS "unanswered_count"
+FROM room r
+LEFT JOIN question q ON q."room_id" = r."id" AND q."deleted_at" IS NULL
+WHERE r."organization_id" = $1
+  AND r."deleted_at" IS NULL
+  AND r."visibility" = 'public'
+  AND r."name" ILIKE '%' || $2::text || '%'
+  AND ($3::text = '' OR ($3::text = 'open') = (r."closed_at" IS NULL))
+GROUP BY r."id"
+HAVING NOT $4::boolean OR (COUNT(q."id"), r."id") < ($5::bigint, $6::uuid)
+ORDER BY "question_count" DESC, r."id" DESC
+LIMIT $7
+`
+
+type ListRoomsByActivityParams struct {
+	OrganizationID      uuid.UUID
+	Search              string
+	Status              string
+	After               bool
+	CursorQuestionCount int64
+	CursorID            uuid.UUID
+	PageSize            int32
+}
+
+type ListRoomsByActivityRow struct {
+	Room            Room
+	QuestionCount   int64
+	UnansweredCount int64
+}
+
+func (q *Queries) ListRoomsByActivity(ctx context.Context, arg ListRoomsByActivityParams) ([]ListRoomsByActivityRow, error) {
+	rows, err := q.db.Query(ctx, listRoomsByActivity, arg.OrganizationID, arg.Search, arg.Status, arg.After, arg.CursorQuestionCount, arg.CursorID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRoomsByActivityRow
+	for rows.Next() {
+		var i ListRoomsByActivityRow
+		if err := rows.Scan(
+			&i.Room.ID,
+			&i.Room.Name,
+			&i.Room.CreatedAt,
+			&i.Room.UpdatedAt,
+			&i.Room.ClosedAt,
+			&i.Room.OwnerTokenHash,
+			&i.Room.DeletedAt,
+			&i.Room.Visibility,
+			&i.Room.JoinCode,
+			&i.Room.Slug,
+			&i.Room.ReactionKinds,
+			&i.Room.PrimaryReaction,
+			&i.Room.Ranking,
+			&i.Room.SpotlightQuestionID,
+			&i.Room.SpotlightedAt,
+			&i.Room.Anonymous,
+			&i.Room.OrganizationID,
+			&i.QuestionCount,
+			&i.UnansweredCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoomsByNewest = `-- name: ListRoomsByNewest :many
+SELECT
+    r.id, r.name, r.created_at, r.updated_at, r.closed_at, r.owner_token_hash, r.deleted_at, r.visibility, r.join_code, r.slug, r.reaction_kinds, r.primary_reaction, r.ranking, r.spotlight_question_id, r.spotlighted_at, r.anonymous, r.organization_id,
+    COUNT(q."id") AS "question_count",
+    COUNT(q."id") FILTER (WHERE NOT q."answered") AS "unanswered_count"
+FROM room r
+LEFT JOIN question q ON q."room_id" = r."id" AND q."deleted_at" IS NULL
+WHERE r."organization_id" = $1
+  AND r."deleted_at" IS NULL
+  AND r."visibility" = 'public'
+  AND r."name" ILIKE '%' || $2::text || '%'
+  AND ($3::text = '' OR ($3::text = 'open') = (r."closed_at" IS NULL))
+  AND (NOT $4::boolean OR (r."created_at", r."id") < ($5::timestamptz, $6::uuid))
+GROUP BY r."id"
+ORDER BY r."created_at" DESC, r."id" DESC
+LIMIT $7
+`
+
+type ListRoomsByNewestParams struct {
+	OrganizationID  uuid.UUID
+	Search          string
+	Status          string
+	After           bool
+	CursorCreatedAt pgtype.Timestamptz
+	CursorID        uuid.UUID
+	PageSize        int32
+}
+
+type ListRoomsByNewestRow struct {
+	Room            Room
+	QuestionCount   int64
+	UnansweredCount int64
+}
+
+func (q *Queries) ListRoomsByNewest(ctx context.Context, arg ListRoomsByNewestParams) ([]ListRoomsByNewestRow, error) {
+	rows, err := q.db.Query(ctx, listRoomsByNewest, arg.OrganizationID, arg.Search, arg.Status, arg.After, arg.CursorCreatedAt, arg.CursorID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRoomsByNewestRow
+	for rows.Next() {
+		var i ListRoomsByNewestRow
+		if err := rows.Scan(
+			&i.Room.ID,
+			&i.Room.Name,
+			&i.Room.CreatedAt,
+			&i.Room.UpdatedAt,
+			&i.Room.ClosedAt,
+			&i.Room.OwnerTokenHash,
+			&i.Room.DeletedAt,
+			&i.Room.Visibility,
+			&i.Room.JoinCode,
+			&i.Room.Slug,
+			&i.Room.ReactionKinds,
+			&i.Room.PrimaryReaction,
+			&i.Room.Ranking,
+			&i.Room.SpotlightQuestionID,
+			&i.Room.SpotlightedAt,
+			&i.Room.Anonymous,
+			&i.Room.OrganizationID,
+			&i.QuestionCount,
+			&i.UnansweredCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markQuestionAsAnswered = `-- name: MarkQuestionAsAnswered :exec
+UPDATE question
+SET
+    "answered" = true
+WHERE "id" = $1
+`
+
+func (q *Queries) MarkQuestionAsAnswered(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markQuestionAsAnswered, id)
+	return err
+}
+
+const pinQuestion = `-- name: PinQuestion :one
+UPDATE question q
+SET
+    "pinned_at" = COALESCE(q."pinned_at", NOW()),
+    "pin_position" = COALESCE(q."pin_position", (
+        SELECT COALESCE(MAX(p."pin_position"), 0) + 1 FROM question p
+        WHERE p."room_id" = q."room_id"
+    ))
+WHERE q."id" = $1
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+`
+
+func (q *Queries) PinQuestion(ctx context.Context, id uuid.UUID) (Question, error) {
+	row := q.db.QueryRow(ctx, pinQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Text,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthorHash,
+		&i.DownvoteCount,
+		&i.SpotlightSeconds,
+		&i.PinnedAt,
+		&i.PinPosition,
+		&i.AuthorName,
+	)
+	return i, err
+}
+
+const reactToQuestion = `-- name: ReactToQuestion :one
+UPDATE question
+SET
+    "reaction_count" = "reaction_count" + 1
+WHERE "id" = $1
+RETURNING "reaction_count"
+`
+
+func (q *Queries) ReactToQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, reactToQuestion, id)
+	var reaction_count int64
+	err := row.Scan(&reaction_count)
+	return reaction_count, err
+}
+
+const removeDownvoteFromQuestion = `-- name: RemoveDownvoteFromQuestion :one
+UPDATE question
+SET
+    "downvote_count" = "downvote_count" - 1
+WHERE "id" = $1 AND "downvote_count" > 0
+RETURNING "downvote_count"
+`
+
+func (q *Queries) RemoveDownvoteFromQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, removeDownvoteFromQuestion, id)
+	var downvote_count int64
+	err := row.Scan(&downvote_count)
+	return downvote_count, err
+}
+
+const removeQuestionReaction = `-- name: RemoveQuestionReaction :one
+UPDATE question_reaction
+SET
+    "count" = "count" - 1
+WHERE "question_id" = $1 AND "kind" = $2 AND "count" > 0
+RETURNING "count"
+`
+
+type RemoveQuestionReactionParams struct {
+	QuestionID uuid.UUID
+	Kind       string
+}
+
+func (q *Queries) RemoveQuestionReaction(ctx context.Context, arg RemoveQuestionReactionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, removeQuestionReaction, arg.QuestionID, arg.Kind)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const removeReactionFromQuestion = `-- name: RemoveReactionFromQuestion :one
+UPDATE question
+SET
+    "reaction_count" = "reaction_count" - 1
 WHERE "id" = $1
 RETURNING "reaction_count"
 `
@@ -205,3 +1625,498 @@ func (q *Queries) RemoveReactionFromQuestion(ctx context.Context, id uuid.UUID)
 	err := row.Scan(&reaction_count)
 	return reaction_count, err
 }
+
+const reopenPoll = `-- name: ReopenPoll :one
+UPDATE poll
+SET
+    "closed_at" = NULL
+WHERE "id" = $1
+RETURNING "id", "room_id", "question", "created_at", "closed_at"
+`
+
+func (q *Queries) ReopenPoll(ctx context.Context, id uuid.UUID) (Poll, error) {
+	row := q.db.QueryRow(ctx, reopenPoll, id)
+	var i Poll
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Question,
+		&i.CreatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const reopenRoom = `-- name: ReopenRoom :one
+UPDATE room
+SET
+    "closed_at" = NULL
+WHERE "id" = $1 AND "organization_id" = $2
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+`
+
+type ReopenRoomParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) ReopenRoom(ctx context.Context, arg ReopenRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, reopenRoom, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const reorderPinnedQuestions = `-- name: ReorderPinnedQuestions :exec
+UPDATE question q
+SET
+    "pin_position" = ordered."position"
+FROM unnest($1::uuid[]) WITH ORDINALITY AS ordered("id", "position")
+WHERE q."id" = ordered."id" AND q."room_id" = $2 AND q."pinned_at" IS NOT NULL
+`
+
+type ReorderPinnedQuestionsParams struct {
+	QuestionIds []uuid.UUID
+	RoomID      uuid.UUID
+}
+
+func (q *Queries) ReorderPinnedQuestions(ctx context.Context, arg ReorderPinnedQuestionsParams) error {
+	_, err := q.db.Exec(ctx, reorderPinnedQuestions, arg.QuestionIds, arg.RoomID)
+	return err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_key
+SET "revoked_at" = COALESCE("revoked_at", NOW())
+WHERE "id" = $1 AND "organization_id" = $2
+RETURNING "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at"
+`
+
+type RevokeAPIKeyParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, revokeAPIKey, arg.ID, arg.OrganizationID)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.OrganizationID,
+		&i.Name,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.Scopes,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const searchRoomQuestions = `-- name: SearchRoomQuestions :many
+SELECT
+    "id", "text", "reaction_count", "answered", "created_at",
+    ts_rank("search_vector", search_query) AS "rank",
+    ts_headline(
+        'english',
+        replace(replace(replace("text", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        search_query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20'
+    ) AS "snippet"
+FROM question, websearch_to_tsquery('english', $1) AS search_query
+WHERE "room_id" = $2 AND "deleted_at" IS NULL AND "search_vector" @@ search_query
+ORDER BY "rank" DESC, "created_at" DESC
+LIMIT $3
+`
+
+type SearchRoomQuestionsParams struct {
+	Query    string
+	RoomID   uuid.UUID
+	PageSize int32
+}
+
+type SearchRoomQuestionsRow struct {
+	ID            uuid.UUID
+	Text          string
+	ReactionCount int64
+	Answered      bool
+	CreatedAt     pgtype.Timestamptz
+	Rank          float32
+	Snippet       string
+}
+
+func (q *Queries) SearchRoomQuestions(ctx context.Context, arg SearchRoomQuestionsParams) ([]SearchRoomQuestionsRow, error) {
+	rows, err := q.db.Query(ctx, searchRoomQuestions, arg.Query, arg.RoomID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchRoomQuestionsRow
+	for rows.Next() {
+		var i SearchRoomQuestionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Text,
+			&i.ReactionCount,
+			&i.Answered,
+			&i.CreatedAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAuditActor = `-- name: SetAuditActor :exec
+SELECT set_config('ama.actor', $1::text, true)
+`
+
+func (q *Queries) SetAuditActor(ctx context.Context, actor string) error {
+	_, err := q.db.Exec(ctx, setAuditActor, actor)
+	return err
+}
+
+const setQuestionReactionCounts = `-- name: SetQuestionReactionCounts :exec
+UPDATE question q
+SET
+    "reaction_count" = COALESCE((
+        SELECT r."count" FROM question_reaction r
+        WHERE r."question_id" = q."id" AND r."kind" = $1::text
+    ), 0)
+WHERE q."room_id" = $2
+`
+
+type SetQuestionReactionCountsParams struct {
+	Kind   string
+	RoomID uuid.UUID
+}
+
+func (q *Queries) SetQuestionReactionCounts(ctx context.Context, arg SetQuestionReactionCountsParams) error {
+	_, err := q.db.Exec(ctx, setQuestionReactionCounts, arg.Kind, arg.RoomID)
+	return err
+}
+
+const setRoomSpotlight = `-- name: SetRoomSpotlight :one
+UPDATE room
+SET
+    "spotlight_question_id" = $1,
+    "spotlighted_at" = CASE WHEN $1::uuid IS NULL THEN NULL ELSE NOW() END
+WHERE "id" = $2 AND "organization_id" = $3
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+`
+
+type SetRoomSpotlightParams struct {
+	QuestionID     pgtype.UUID
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) SetRoomSpotlight(ctx context.Context, arg SetRoomSpotlightParams) (Room, error) {
+	row := q.db.QueryRow(ctx, setRoomSpotlight, arg.QuestionID, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const softDeleteQuestion = `-- name: SoftDeleteQuestion :execrows
+UPDATE question
+SET
+    "deleted_at" = NOW()
+WHERE "id" = $1 AND "deleted_at" IS NULL
+`
+
+func (q *Queries) SoftDeleteQuestion(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteQuestion, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteRoom = `-- name: SoftDeleteRoom :execrows
+UPDATE room
+SET
+    "deleted_at" = NOW()
+WHERE "id" = $1 AND "organization_id" = $2 AND "deleted_at" IS NULL
+`
+
+type SoftDeleteRoomParams struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) SoftDeleteRoom(ctx context.Context, arg SoftDeleteRoomParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteRoom, arg.ID, arg.OrganizationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_key
+SET "last_used_at" = NOW()
+WHERE "id" = $1 AND ("last_used_at" IS NULL OR "last_used_at" < NOW() - INTERVAL '1 minute')
+`
+
+func (q *Queries) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchAPIKey, id)
+	return err
+}
+
+const unpinQuestion = `-- name: UnpinQuestion :one
+UPDATE question
+SET
+    "pinned_at" = NULL,
+    "pin_position" = NULL
+WHERE "id" = $1
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+`
+
+func (q *Queries) UnpinQuestion(ctx context.Context, id uuid.UUID) (Question, error) {
+	row := q.db.QueryRow(ctx, unpinQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Text,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthorHash,
+		&i.DownvoteCount,
+		&i.SpotlightSeconds,
+		&i.PinnedAt,
+		&i.PinPosition,
+		&i.AuthorName,
+	)
+	return i, err
+}
+
+const updateQuestionText = `-- name: UpdateQuestionText :one
+UPDATE question
+SET
+    "text" = $1,
+    "updated_at" = NOW()
+WHERE "id" = $2
+  AND "deleted_at" IS NULL
+  AND "downvote_count" = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM question_reaction
+    WHERE "question_id" = $2 AND "count" > 0
+  )
+  AND NOT "answered"
+  AND "created_at" >= NOW() - make_interval(secs => $3::float8)
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+`
+
+type UpdateQuestionTextParams struct {
+	Text              string
+	ID                uuid.UUID
+	EditWindowSeconds float64
+}
+
+func (q *Queries) UpdateQuestionText(ctx context.Context, arg UpdateQuestionTextParams) (Question, error) {
+	row := q.db.QueryRow(ctx, updateQuestionText, arg.Text, arg.ID, arg.EditWindowSeconds)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Text,
+		&i.ReactionCount,
+		&i.Answered,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AuthorHash,
+		&i.DownvoteCount,
+		&i.SpotlightSeconds,
+		&i.PinnedAt,
+		&i.PinPosition,
+		&i.AuthorName,
+	)
+	return i, err
+}
+
+const updateRoomAnonymity = `-- name: UpdateRoomAnonymity :one
+UPDATE room
+SET
+    "anonymous" = $1
+WHERE "id" = $2 AND "organization_id" = $3
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+`
+
+type UpdateRoomAnonymityParams struct {
+	Anonymous      bool
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) UpdateRoomAnonymity(ctx context.Context, arg UpdateRoomAnonymityParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoomAnonymity, arg.Anonymous, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const updateRoomRanking = `-- name: UpdateRoomRanking :one
+UPDATE room
+SET
+    "ranking" = $1
+WHERE "id" = $2 AND "organization_id" = $3
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+`
+
+type UpdateRoomRankingParams struct {
+	Ranking        string
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+}
+
+func (q *Queries) UpdateRoomRanking(ctx context.Context, arg UpdateRoomRankingParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoomRanking, arg.Ranking, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const updateRoomReactions = `-- name: UpdateRoomReactions :one
+UPDATE room
+SET
+    "reaction_kinds" = $1,
+    "primary_reaction" = $2
+WHERE "id" = $3 AND "organization_id" = $4
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+`
+
+type UpdateRoomReactionsParams struct {
+	ReactionKinds   []string
+	PrimaryReaction string
+	ID              uuid.UUID
+	OrganizationID  uuid.UUID
+}
+
+func (q *Queries) UpdateRoomReactions(ctx context.Context, arg UpdateRoomReactionsParams) (Room, error) {
+	row := q.db.QueryRow(ctx, updateRoomReactions, arg.ReactionKinds, arg.PrimaryReaction, arg.ID, arg.OrganizationID)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.OwnerTokenHash,
+		&i.DeletedAt,
+		&i.Visibility,
+		&i.JoinCode,
+		&i.Slug,
+		&i.ReactionKinds,
+		&i.PrimaryReaction,
+		&i.Ranking,
+		&i.SpotlightQuestionID,
+		&i.SpotlightedAt,
+		&i.Anonymous,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const voteInPoll = `-- name: VoteInPoll :exec
+INSERT INTO poll_vote ("poll_id", "participant_hash", "option_id")
+VALUES ($1, $2, $3)
+ON CONFLICT ("poll_id", "participant_hash") DO UPDATE
+SET
+    "option_id" = EXCLUDED."option_id",
+    "created_at" = NOW()
+`
+
+type VoteInPollParams struct {
+	PollID          uuid.UUID
+	ParticipantHash []byte
+	OptionID        uuid.UUID
+}
+
+func (q *Queries) VoteInPoll(ctx context.Context, arg VoteInPollParams) error {
+	_, err := q.db.Exec(ctx, voteInPoll, arg.PollID, arg.ParticipantHash, arg.OptionID)
+	return err
+}
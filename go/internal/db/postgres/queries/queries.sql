@@ -1,37 +1,175 @@
 -- name: GetRoom :one
 SELECT 
-    "id", "name", "created_at", "updated_at"
+    "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
 FROM room
-WHERE "id" = $1;
+WHERE "id" = @id AND "organization_id" = @organization_id AND "deleted_at" IS NULL;
+
+-- name: GetRoomBySlug :one
+SELECT 
+    "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+FROM room
+WHERE "slug" = @slug AND "organization_id" = @organization_id AND "deleted_at" IS NULL;
 
 -- name: GetRooms :many
 SELECT 
-    "id", "name", "created_at", "updated_at"
-FROM room;
+    "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id"
+FROM room
+WHERE "organization_id" = $1 AND "deleted_at" IS NULL;
+
+-- name: GetRoomsWithQuestionCount :many
+SELECT
+    r."id", r."name", r."created_at", r."updated_at", r."closed_at", r."owner_token_hash", r."deleted_at", r."visibility", r."join_code", r."slug", r."reaction_kinds", r."primary_reaction", r."ranking", r."spotlight_question_id", r."spotlighted_at", r."anonymous", r."organization_id",
+    COUNT(q."id") AS "question_count"
+FROM room r
+LEFT JOIN question q ON q."room_id" = r."id" AND q."deleted_at" IS NULL
+WHERE r."organization_id" = @organization_id
+  AND (@include_deleted::boolean OR r."deleted_at" IS NULL)
+GROUP BY r."id"
+ORDER BY r."created_at";
 
 -- name: CreateRoom :one
 INSERT INTO room 
-  ("name") VALUES
-  ($1)
-RETURNING "id", "name", "created_at", "updated_at";
+  ("name", "owner_token_hash", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "anonymous", "organization_id") VALUES
+  ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: CloseRoom :one
+UPDATE room
+SET
+    "closed_at" = COALESCE("closed_at", NOW())
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: ReopenRoom :one
+UPDATE room
+SET
+    "closed_at" = NULL
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: UpdateRoomReactions :one
+UPDATE room
+SET
+    "reaction_kinds" = @reaction_kinds,
+    "primary_reaction" = @primary_reaction
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: UpdateRoomRanking :one
+UPDATE room
+SET
+    "ranking" = @ranking
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: UpdateRoomAnonymity :one
+UPDATE room
+SET
+    "anonymous" = @anonymous
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: SoftDeleteRoom :execrows
+UPDATE room
+SET
+    "deleted_at" = NOW()
+WHERE "id" = @id AND "organization_id" = @organization_id AND "deleted_at" IS NULL;
+
+-- name: DeleteRoom :execrows
+DELETE FROM room
+WHERE "id" = @id AND "organization_id" = @organization_id;
+
+-- name: AddSpotlightTime :exec
+UPDATE question q
+SET
+    "spotlight_seconds" = q."spotlight_seconds" + GREATEST(EXTRACT(EPOCH FROM NOW() - r."spotlighted_at"), 0)::float8
+FROM room r
+WHERE r."id" = @id AND r."organization_id" = @organization_id AND q."id" = r."spotlight_question_id";
+
+-- name: SetRoomSpotlight :one
+UPDATE room
+SET
+    "spotlight_question_id" = sqlc.narg(question_id),
+    "spotlighted_at" = CASE WHEN sqlc.narg(question_id)::uuid IS NULL THEN NULL ELSE NOW() END
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "name", "created_at", "updated_at", "closed_at", "owner_token_hash", "deleted_at", "visibility", "join_code", "slug", "reaction_kinds", "primary_reaction", "ranking", "spotlight_question_id", "spotlighted_at", "anonymous", "organization_id";
+
+-- name: CreateRoomGrant :exec
+INSERT INTO room_grant
+  ("room_id", "participant_hash")
+  VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: HasRoomGrant :one
+SELECT EXISTS (
+    SELECT 1 FROM room_grant
+    WHERE "room_id" = $1 AND "participant_hash" = $2
+);
 
 -- name: GetQuestion :one
 SELECT
-    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at"
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
 FROM question
-WHERE "id" = $1;
+WHERE "id" = $1 AND "deleted_at" IS NULL;
 
 -- name: GetRoomQuestions :many
 SELECT
-    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at"
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
 FROM question
-WHERE "room_id" = $1;
+WHERE "room_id" = $1 AND "deleted_at" IS NULL;
+
+-- name: GetRankedRoomQuestions :many
+SELECT
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+FROM question
+WHERE "room_id" = @room_id AND "deleted_at" IS NULL
+ORDER BY
+    "pin_position" NULLS LAST,
+    CASE @ranking::text
+        WHEN 'wilson' THEN question_wilson_score("reaction_count", "downvote_count")
+        WHEN 'hot' THEN question_hot_score("reaction_count", "downvote_count", "created_at", NOW())
+        ELSE ("reaction_count" - "downvote_count")::float8
+    END DESC,
+    "created_at", "id"
+LIMIT sqlc.narg(page_size) OFFSET @page_offset;
+
+-- name: PinQuestion :one
+UPDATE question q
+SET
+    "pinned_at" = COALESCE(q."pinned_at", NOW()),
+    "pin_position" = COALESCE(q."pin_position", (
+        SELECT COALESCE(MAX(p."pin_position"), 0) + 1 FROM question p
+        WHERE p."room_id" = q."room_id"
+    ))
+WHERE q."id" = $1
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name";
+
+-- name: UnpinQuestion :one
+UPDATE question
+SET
+    "pinned_at" = NULL,
+    "pin_position" = NULL
+WHERE "id" = $1
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name";
+
+-- name: GetRoomPinnedQuestionIDs :many
+SELECT "id"
+FROM question
+WHERE "room_id" = $1 AND "pinned_at" IS NOT NULL AND "deleted_at" IS NULL
+ORDER BY "pin_position";
+
+-- name: ReorderPinnedQuestions :exec
+UPDATE question q
+SET
+    "pin_position" = ordered."position"
+FROM unnest(@question_ids::uuid[]) WITH ORDINALITY AS ordered("id", "position")
+WHERE q."id" = ordered."id" AND q."room_id" = @room_id AND q."pinned_at" IS NOT NULL;
 
 -- name: CreateQuestion :one
 INSERT INTO question 
-  ("room_id", "text")
-  VALUES ($1, $2)
-RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at";
+  ("room_id", "text", "author_hash", "author_name")
+  VALUES ($1, $2, $3, $4)
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name";
 
 -- name: ReactToQuestion :one
 UPDATE question
@@ -47,8 +185,334 @@ SET
 WHERE "id" = $1
 RETURNING "reaction_count";
 
+-- name: DownvoteQuestion :one
+UPDATE question
+SET
+    "downvote_count" = "downvote_count" + 1
+WHERE "id" = $1
+RETURNING "downvote_count";
+
+-- name: RemoveDownvoteFromQuestion :one
+UPDATE question
+SET
+    "downvote_count" = "downvote_count" - 1
+WHERE "id" = $1 AND "downvote_count" > 0
+RETURNING "downvote_count";
+
+-- name: AddQuestionReaction :one
+INSERT INTO question_reaction
+  ("question_id", "kind", "count")
+  VALUES ($1, $2, 1)
+ON CONFLICT ("question_id", "kind") DO UPDATE
+SET "count" = question_reaction."count" + 1
+RETURNING "count";
+
+-- name: RemoveQuestionReaction :one
+UPDATE question_reaction
+SET
+    "count" = "count" - 1
+WHERE "question_id" = $1 AND "kind" = $2 AND "count" > 0
+RETURNING "count";
+
+-- name: GetQuestionReactions :many
+SELECT
+    "question_id", "kind", "count"
+FROM question_reaction
+WHERE "question_id" = $1;
+
+-- name: GetRoomQuestionReactions :many
+SELECT
+    r."question_id", r."kind", r."count"
+FROM question_reaction r
+JOIN question q ON q."id" = r."question_id"
+WHERE q."room_id" = $1 AND q."deleted_at" IS NULL;
+
+-- name: CreateQuestionReactionsFromCounts :exec
+INSERT INTO question_reaction ("question_id", "kind", "count")
+SELECT "id", @kind::text, "reaction_count"
+FROM question
+WHERE "room_id" = @room_id AND "reaction_count" > 0
+ON CONFLICT DO NOTHING;
+
+-- name: SetQuestionReactionCounts :exec
+UPDATE question q
+SET
+    "reaction_count" = COALESCE((
+        SELECT r."count" FROM question_reaction r
+        WHERE r."question_id" = q."id" AND r."kind" = @kind::text
+    ), 0)
+WHERE q."room_id" = @room_id;
+
 -- name: MarkQuestionAsAnswered :exec
 UPDATE question
 SET
     "answered" = true
 WHERE "id" = $1;
+
+-- name: UpdateQuestionText :one
+UPDATE question
+SET
+    "text" = @text,
+    "updated_at" = NOW()
+WHERE "id" = @id
+  AND "deleted_at" IS NULL
+  AND "downvote_count" = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM question_reaction
+    WHERE "question_id" = @id AND "count" > 0
+  )
+  AND NOT "answered"
+  AND "created_at" >= NOW() - make_interval(secs => @edit_window_seconds::float8)
+RETURNING "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name";
+
+-- name: CreateQuestionEdit :exec
+INSERT INTO question_edit
+  ("question_id", "text")
+  VALUES ($1, $2);
+
+-- name: GetQuestionEdits :many
+SELECT
+    "id", "question_id", "text", "edited_at"
+FROM question_edit
+WHERE "question_id" = $1
+ORDER BY "edited_at", "id";
+
+-- name: CreateComment :one
+INSERT INTO comment
+  ("question_id", "text", "author_hash")
+  VALUES ($1, $2, $3)
+RETURNING "id", "question_id", "text", "author_hash", "created_at";
+
+-- name: GetComment :one
+SELECT
+    "id", "question_id", "text", "author_hash", "created_at"
+FROM comment
+WHERE "id" = $1;
+
+-- name: GetQuestionComments :many
+SELECT
+    "id", "question_id", "text", "author_hash", "created_at"
+FROM comment
+WHERE "question_id" = $1
+ORDER BY "created_at", "id";
+
+-- name: DeleteComment :execrows
+DELETE FROM comment
+WHERE "id" = $1;
+
+-- name: CountQuestionComments :one
+SELECT COUNT(*) AS "comment_count"
+FROM comment
+WHERE "question_id" = $1;
+
+-- name: GetRoomCommentCounts :many
+SELECT
+    c."question_id", COUNT(*) AS "comment_count"
+FROM comment c
+JOIN question q ON q."id" = c."question_id"
+WHERE q."room_id" = $1 AND q."deleted_at" IS NULL
+GROUP BY c."question_id";
+
+-- name: SoftDeleteQuestion :execrows
+UPDATE question
+SET
+    "deleted_at" = NOW()
+WHERE "id" = $1 AND "deleted_at" IS NULL;
+
+-- name: DeleteQuestion :execrows
+DELETE FROM question
+WHERE "id" = $1;
+
+-- name: DeleteRoomQuestions :execrows
+DELETE FROM question
+WHERE "room_id" = $1;
+
+-- name: GetRoomQuestionsByPopularity :many
+SELECT
+    "id", "room_id", "text", "reaction_count", "answered", "created_at", "updated_at", "deleted_at", "author_hash", "downvote_count", "spotlight_seconds", "pinned_at", "pin_position", "author_name"
+FROM question
+WHERE "room_id" = $1 AND "deleted_at" IS NULL
+ORDER BY "reaction_count" DESC, "created_at" ASC;
+
+-- name: CreateQuestions :copyfrom
+INSERT INTO question
+  ("id", "room_id", "text", "reaction_count", "answered")
+  VALUES ($1, $2, $3, $4, $5);
+
+-- name: CreatePoll :one
+INSERT INTO poll
+  ("room_id", "question")
+  VALUES ($1, $2)
+RETURNING "id", "room_id", "question", "created_at", "closed_at";
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_option ("poll_id", "position", "text")
+SELECT @poll_id::uuid, options."position", options."text"
+FROM unnest(@texts::text[]) WITH ORDINALITY AS options("text", "position");
+
+-- name: GetPoll :one
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM poll
+WHERE "id" = $1;
+
+-- name: GetRoomPolls :many
+SELECT
+    "id", "room_id", "question", "created_at", "closed_at"
+FROM poll
+WHERE "room_id" = $1
+ORDER BY "created_at", "id";
+
+-- name: ClosePoll :one
+UPDATE poll
+SET
+    "closed_at" = COALESCE("closed_at", NOW())
+WHERE "id" = $1
+RETURNING "id", "room_id", "question", "created_at", "closed_at";
+
+-- name: ReopenPoll :one
+UPDATE poll
+SET
+    "closed_at" = NULL
+WHERE "id" = $1
+RETURNING "id", "room_id", "question", "created_at", "closed_at";
+
+-- name: VoteInPoll :exec
+INSERT INTO poll_vote ("poll_id", "participant_hash", "option_id")
+VALUES ($1, $2, $3)
+ON CONFLICT ("poll_id", "participant_hash") DO UPDATE
+SET
+    "option_id" = EXCLUDED."option_id",
+    "created_at" = NOW();
+
+-- name: GetPollOptions :many
+SELECT
+    o."id", o."poll_id", o."position", o."text", COUNT(v."option_id") AS "vote_count"
+FROM poll_option o
+LEFT JOIN poll_vote v ON v."option_id" = o."id"
+WHERE o."poll_id" = $1
+GROUP BY o."id"
+ORDER BY o."position";
+
+-- name: GetRoomPollOptions :many
+SELECT
+    o."id", o."poll_id", o."position", o."text", COUNT(v."option_id") AS "vote_count"
+FROM poll_option o
+JOIN poll p ON p."id" = o."poll_id"
+LEFT JOIN poll_vote v ON v."option_id" = o."id"
+WHERE p."room_id" = $1
+GROUP BY o."id"
+ORDER BY o."poll_id", o."position";
+
+-- name: SetAuditActor :exec
+SELECT set_config('ama.actor', sqlc.arg(actor)::text, true);
+
+-- name: GetRoomAuditLog :many
+SELECT
+    "id", "room_id", "entity", "entity_id", "action", "actor", "changes", "created_at"
+FROM audit_log
+WHERE "room_id" = $1
+ORDER BY "id";
+
+-- name: ListRoomsByNewest :many
+SELECT
+    sqlc.embed(r),
+    COUNT(q."id") AS "question_count",
+    COUNT(q."id") FILTER (WHERE NOT q."answered") AS "unanswered_count"
+FROM room r
+LEFT JOIN question q ON q."room_id" = r."id" AND q."deleted_at" IS NULL
+WHERE r."organization_id" = @organization_id
+  AND r."deleted_at" IS NULL
+  AND r."visibility" = 'public'
+  AND r."name" ILIKE '%' || @search::text || '%'
+  AND (@status::text = '' OR (@status::text = 'open') = (r."closed_at" IS NULL))
+  AND (NOT @after::boolean OR (r."created_at", r."id") < (@cursor_created_at::timestamptz, @cursor_id::uuid))
+GROUP BY r."id"
+ORDER BY r."created_at" DESC, r."id" DESC
+LIMIT @page_size;
+
+-- name: ListRoomsByActivity :many
+SELECT
+    sqlc.embed(r),
+    COUNT(q."id") AS "question_count",
+    COUNT(q."id") FILTER (WHERE NOT q."answered") AS "unanswered_count"
+FROM room r
+LEFT JOIN question q ON q."room_id" = r."id" AND q."deleted_at" IS NULL
+WHERE r."organization_id" = @organization_id
+  AND r."deleted_at" IS NULL
+  AND r."visibility" = 'public'
+  AND r."name" ILIKE '%' || @search::text || '%'
+  AND (@status::text = '' OR (@status::text = 'open') = (r."closed_at" IS NULL))
+GROUP BY r."id"
+HAVING NOT @after::boolean OR (COUNT(q."id"), r."id") < (@cursor_question_count::bigint, @cursor_id::uuid)
+ORDER BY "question_count" DESC, r."id" DESC
+LIMIT @page_size;
+
+-- name: SearchRoomQuestions :many
+SELECT
+    "id", "text", "reaction_count", "answered", "created_at",
+    ts_rank("search_vector", search_query) AS "rank",
+    ts_headline(
+        'english',
+        replace(replace(replace("text", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        search_query,
+        'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20'
+    ) AS "snippet"
+FROM question, websearch_to_tsquery('english', @query) AS search_query
+WHERE "room_id" = @room_id AND "deleted_at" IS NULL AND "search_vector" @@ search_query
+ORDER BY "rank" DESC, "created_at" DESC
+LIMIT @page_size;
+
+-- name: CreateOrganization :one
+INSERT INTO organization
+  ("name", "slug")
+  VALUES ($1, $2)
+RETURNING "id", "name", "slug", "created_at";
+
+-- name: GetOrganization :one
+SELECT
+    "id", "name", "slug", "created_at"
+FROM organization
+WHERE "id" = $1;
+
+-- name: GetOrganizationBySlug :one
+SELECT
+    "id", "name", "slug", "created_at"
+FROM organization
+WHERE "slug" = $1;
+
+-- name: GetOrganizations :many
+SELECT
+    "id", "name", "slug", "created_at"
+FROM organization
+ORDER BY "created_at";
+
+-- name: CreateAPIKey :one
+INSERT INTO api_key
+  ("organization_id", "name", "key_hash", "scopes")
+  VALUES ($1, $2, $3, $4)
+RETURNING "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at";
+
+-- name: GetAPIKeyByHash :one
+SELECT
+    "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at"
+FROM api_key
+WHERE "key_hash" = $1 AND "revoked_at" IS NULL;
+
+-- name: GetOrganizationAPIKeys :many
+SELECT
+    "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at"
+FROM api_key
+WHERE "organization_id" = $1
+ORDER BY "created_at";
+
+-- name: RevokeAPIKey :one
+UPDATE api_key
+SET "revoked_at" = COALESCE("revoked_at", NOW())
+WHERE "id" = @id AND "organization_id" = @organization_id
+RETURNING "id", "organization_id", "name", "key_hash", "created_at", "scopes", "last_used_at", "revoked_at";
+
+-- name: TouchAPIKey :exec
+UPDATE api_key
+SET "last_used_at" = NOW()
+WHERE "id" = $1 AND ("last_used_at" IS NULL OR "last_used_at" < NOW() - INTERVAL '1 minute');
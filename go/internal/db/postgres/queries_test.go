@@ -0,0 +1,1236 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres/postgrestest"
+)
+
+var (
+	server       *postgrestest.Server
+	serverFailed error
+)
+
+func TestMain(m *testing.M) {
+	server, serverFailed = postgrestest.Start(context.Background())
+	if serverFailed != nil {
+		fmt.Fprintln(os.Stderr, "skipping postgres integration tests:", serverFailed)
+	}
+
+	code := m.Run()
+
+	if server != nil {
+		server.Stop()
+	}
+
+	os.Exit(code)
+}
+
+func newTestDatabase(t *testing.T) (*postgres.Queries, *pgxpool.Pool) {
+	t.Helper()
+
+	if server == nil {
+		t.Skip("postgres is not available:", serverFailed)
+	}
+
+	pool := server.NewDatabase(t)
+	return postgres.New(pool), pool
+}
+
+// roomParams fills in what the service would for a public room.
+func roomParams(name, slug string) postgres.CreateRoomParams {
+	return postgres.CreateRoomParams{
+		Name:            name,
+		OwnerTokenHash:  []byte("owner"),
+		Visibility:      "public",
+		Slug:            slug,
+		ReactionKinds:   []string{"upvote"},
+		PrimaryReaction: "upvote",
+		Ranking:         "score",
+		OrganizationID:  db.DefaultOrganizationID,
+	}
+}
+
+func mustCreateRoom(t *testing.T, query *postgres.Queries, name string) postgres.Room {
+	t.Helper()
+
+	room, err := query.CreateRoom(context.Background(), roomParams(name, uuid.NewString()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return room
+}
+
+func mustCreateQuestion(t *testing.T, query *postgres.Queries, roomID uuid.UUID, text string) postgres.Question {
+	t.Helper()
+
+	question, err := query.CreateQuestion(context.Background(), postgres.CreateQuestionParams{RoomID: roomID, Text: text})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return question
+}
+
+func TestQueries(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, ctx context.Context, query *postgres.Queries)
+	}{
+		{"CreateRoom", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			if room.ID == uuid.Nil || room.Name != "Go AMA" || !room.CreatedAt.Valid || !room.UpdatedAt.Valid {
+				t.Fatalf("unexpected room %+v", room)
+			}
+		}},
+		{"GetRoom", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			created := mustCreateRoom(t, query, "Go AMA")
+
+			room, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: created.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if room.ID != created.ID || room.Name != created.Name {
+				t.Fatalf("expected %+v, got %+v", created, room)
+			}
+
+			if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: uuid.New(), OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+			}
+		}},
+		{"GetRoomBySlug", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room, err := query.CreateRoom(ctx, roomParams("Go AMA", "go-ama"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			found, err := query.GetRoomBySlug(ctx, postgres.GetRoomBySlugParams{Slug: "go-ama", OrganizationID: db.DefaultOrganizationID})
+			if err != nil || found.ID != room.ID {
+				t.Fatalf("expected the room by its slug, got %+v, %v", found, err)
+			}
+
+			if _, err := query.CreateRoom(ctx, roomParams("Go AMA", "go-ama")); err == nil {
+				t.Fatal("expected a taken slug to be rejected")
+			}
+
+			if _, err := query.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := query.GetRoomBySlug(ctx, postgres.GetRoomBySlugParams{Slug: "go-ama", OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected soft deleted rooms to be hidden, got %v", err)
+			}
+		}},
+		{"GetRooms", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			mustCreateRoom(t, query, "First")
+			mustCreateRoom(t, query, "Second")
+
+			rooms, err := query.GetRooms(ctx, db.DefaultOrganizationID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rooms) != 2 {
+				t.Fatalf("expected 2 rooms, got %d", len(rooms))
+			}
+		}},
+		{"GetRoomsWithQuestionCount", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			busy := mustCreateRoom(t, query, "Busy")
+			mustCreateRoom(t, query, "Empty")
+			mustCreateQuestion(t, query, busy.ID, "First")
+			mustCreateQuestion(t, query, busy.ID, "Second")
+
+			deleted := mustCreateRoom(t, query, "Deleted")
+			if _, err := query.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: deleted.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+
+			rooms, err := query.GetRoomsWithQuestionCount(ctx, postgres.GetRoomsWithQuestionCountParams{OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rooms) != 2 || rooms[0].QuestionCount != 2 || rooms[1].QuestionCount != 0 {
+				t.Fatalf("unexpected rooms %+v", rooms)
+			}
+
+			rooms, err = query.GetRoomsWithQuestionCount(ctx, postgres.GetRoomsWithQuestionCountParams{OrganizationID: db.DefaultOrganizationID, IncludeDeleted: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rooms) != 3 || rooms[2].ID != deleted.ID || !rooms[2].DeletedAt.Valid {
+				t.Fatalf("expected the deleted room to be listed on request, got %+v", rooms)
+			}
+		}},
+		{"ListRoomsByNewest", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			first := mustCreateRoom(t, query, "Go AMA")
+			second := mustCreateRoom(t, query, "Rust AMA")
+			third := mustCreateRoom(t, query, "Go 100% AMA")
+			mustCreateQuestion(t, query, first.ID, "Generics?")
+			if _, err := query.CloseRoom(ctx, postgres.CloseRoomParams{ID: third.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := query.ListRoomsByNewest(ctx, postgres.ListRoomsByNewestParams{OrganizationID: db.DefaultOrganizationID, PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 3 || rows[0].Room.ID != third.ID || rows[2].Room.ID != first.ID || rows[2].QuestionCount != 1 || rows[2].UnansweredCount != 1 {
+				t.Fatalf("unexpected rows %+v", rows)
+			}
+
+			rows, err = query.ListRoomsByNewest(ctx, postgres.ListRoomsByNewestParams{OrganizationID: db.DefaultOrganizationID, Search: "go", Status: "open", PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 || rows[0].Room.ID != first.ID {
+				t.Fatalf("expected only the open Go room, got %+v", rows)
+			}
+
+			rows, err = query.ListRoomsByNewest(ctx, postgres.ListRoomsByNewestParams{
+				OrganizationID:  db.DefaultOrganizationID,
+				After:           true,
+				CursorCreatedAt: second.CreatedAt,
+				CursorID:        second.ID,
+				PageSize:        10,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 || rows[0].Room.ID != first.ID {
+				t.Fatalf("expected the rooms after the cursor, got %+v", rows)
+			}
+		}},
+		{"ListRoomsByActivity", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			quiet := mustCreateRoom(t, query, "Quiet")
+			busy := mustCreateRoom(t, query, "Busy")
+			mustCreateQuestion(t, query, busy.ID, "First")
+			answered := mustCreateQuestion(t, query, busy.ID, "Second")
+			if err := query.MarkQuestionAsAnswered(ctx, answered.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := query.ListRoomsByActivity(ctx, postgres.ListRoomsByActivityParams{OrganizationID: db.DefaultOrganizationID, PageSize: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 || rows[0].Room.ID != busy.ID || rows[0].QuestionCount != 2 || rows[0].UnansweredCount != 1 {
+				t.Fatalf("unexpected rows %+v", rows)
+			}
+
+			rows, err = query.ListRoomsByActivity(ctx, postgres.ListRoomsByActivityParams{
+				OrganizationID:      db.DefaultOrganizationID,
+				After:               true,
+				CursorQuestionCount: rows[0].QuestionCount,
+				CursorID:            rows[0].Room.ID,
+				PageSize:            10,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 || rows[0].Room.ID != quiet.ID {
+				t.Fatalf("expected the rooms after the cursor, got %+v", rows)
+			}
+		}},
+		{"room visibility and grants", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			public := mustCreateRoom(t, query, "Public")
+			params := roomParams("Private", "private")
+			params.Visibility = "private"
+			params.JoinCode = pgtype.Text{String: "K7QM-3XHP", Valid: true}
+			private, err := query.CreateRoom(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if private.Visibility != "private" || private.JoinCode.String != "K7QM-3XHP" {
+				t.Fatalf("unexpected room %+v", private)
+			}
+
+			params = roomParams("Secret", "secret")
+			params.Visibility = "secret"
+			if _, err := query.CreateRoom(ctx, params); err == nil {
+				t.Fatal("expected an unknown visibility to be rejected")
+			}
+
+			rows, err := query.ListRoomsByNewest(ctx, postgres.ListRoomsByNewestParams{OrganizationID: db.DefaultOrganizationID, PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 || rows[0].Room.ID != public.ID {
+				t.Fatalf("expected only the public room, got %+v", rows)
+			}
+
+			grant := postgres.CreateRoomGrantParams{RoomID: private.ID, ParticipantHash: []byte("alice")}
+			for range 2 {
+				if err := query.CreateRoomGrant(ctx, grant); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			granted, err := query.HasRoomGrant(ctx, postgres.HasRoomGrantParams{RoomID: private.ID, ParticipantHash: []byte("alice")})
+			if err != nil || !granted {
+				t.Fatalf("expected alice to be granted, got %v, %v", granted, err)
+			}
+			granted, err = query.HasRoomGrant(ctx, postgres.HasRoomGrantParams{RoomID: private.ID, ParticipantHash: []byte("bob")})
+			if err != nil || granted {
+				t.Fatalf("expected bob not to be granted, got %v, %v", granted, err)
+			}
+		}},
+		{"question reactions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			params := roomParams("Go AMA", "go-ama")
+			params.ReactionKinds = []string{"upvote", "me-too"}
+			room, err := query.CreateRoom(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			for _, kind := range []string{"upvote", "me-too", "me-too"} {
+				if _, err := query.AddQuestionReaction(ctx, postgres.AddQuestionReactionParams{QuestionID: question.ID, Kind: kind}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			count, err := query.RemoveQuestionReaction(ctx, postgres.RemoveQuestionReactionParams{QuestionID: question.ID, Kind: "upvote"})
+			if err != nil || count != 0 {
+				t.Fatalf("expected the upvote to be taken back, got %d, %v", count, err)
+			}
+			if _, err := query.RemoveQuestionReaction(ctx, postgres.RemoveQuestionReactionParams{QuestionID: question.ID, Kind: "upvote"}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected counts never to go below zero, got %v", err)
+			}
+
+			reactions, err := query.GetRoomQuestionReactions(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts := make(map[string]int64)
+			for _, reaction := range reactions {
+				counts[reaction.Kind] = reaction.Count
+			}
+			if counts["upvote"] != 0 || counts["me-too"] != 2 {
+				t.Fatalf("unexpected counts %v", counts)
+			}
+
+			if err := query.SetQuestionReactionCounts(ctx, postgres.SetQuestionReactionCountsParams{Kind: "me-too", RoomID: room.ID}); err != nil {
+				t.Fatal(err)
+			}
+			question, err = query.GetQuestion(ctx, question.ID)
+			if err != nil || question.ReactionCount != 2 {
+				t.Fatalf("expected the reaction count of the new primary kind, got %d, %v", question.ReactionCount, err)
+			}
+
+			if _, err := query.UpdateRoomReactions(ctx, postgres.UpdateRoomReactionsParams{ReactionKinds: []string{"upvote"}, PrimaryReaction: "me-too", ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err == nil {
+				t.Fatal("expected a primary reaction outside of the kinds to be rejected")
+			}
+		}},
+		{"CreateQuestionReactionsFromCounts and GetQuestionReactions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			popular := uuid.New()
+			if _, err := query.CreateQuestions(ctx, []postgres.CreateQuestionsParams{
+				{ID: popular, RoomID: room.ID, Text: "Generics?", ReactionCount: 3},
+				{ID: uuid.New(), RoomID: room.ID, Text: "Iterators?"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			params := postgres.CreateQuestionReactionsFromCountsParams{Kind: "upvote", RoomID: room.ID}
+			if err := query.CreateQuestionReactionsFromCounts(ctx, params); err != nil {
+				t.Fatal(err)
+			}
+			// Running it again leaves the existing counts alone.
+			if err := query.CreateQuestionReactionsFromCounts(ctx, params); err != nil {
+				t.Fatal(err)
+			}
+
+			reactions, err := query.GetQuestionReactions(ctx, popular)
+			if err != nil || len(reactions) != 1 || reactions[0].Kind != "upvote" || reactions[0].Count != 3 {
+				t.Fatalf("expected 3 upvotes, got %+v, %v", reactions, err)
+			}
+
+			// Questions without reactions get no rows.
+			all, err := query.GetRoomQuestionReactions(ctx, room.ID)
+			if err != nil || len(all) != 1 {
+				t.Fatalf("expected a single reaction row in the room, got %+v, %v", all, err)
+			}
+		}},
+		{"SearchRoomQuestions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			pricing := mustCreateQuestion(t, query, room.ID, "What about <b>pricing</b> for teams?")
+			mustCreateQuestion(t, query, room.ID, "Is there a free tier?")
+
+			rows, err := query.SearchRoomQuestions(ctx, postgres.SearchRoomQuestionsParams{Query: "price", RoomID: room.ID, PageSize: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 || rows[0].ID != pricing.ID || rows[0].Rank <= 0 {
+				t.Fatalf("expected the stemmed match, got %+v", rows)
+			}
+			if !strings.Contains(rows[0].Snippet, "<mark>pricing</mark>") || strings.Contains(rows[0].Snippet, "<b>") {
+				t.Fatalf("expected an escaped, highlighted snippet, got %q", rows[0].Snippet)
+			}
+		}},
+		{"GetRankedRoomQuestions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			popular := mustCreateQuestion(t, query, room.ID, "Generics?")
+			unanimous := mustCreateQuestion(t, query, room.ID, "Iterators?")
+
+			for range 10 {
+				if _, err := query.ReactToQuestion(ctx, popular.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for range 6 {
+				if _, err := query.DownvoteQuestion(ctx, popular.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for range 3 {
+				if _, err := query.ReactToQuestion(ctx, unanimous.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := query.RemoveDownvoteFromQuestion(ctx, unanimous.ID); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected downvotes never to go below zero, got %v", err)
+			}
+
+			for ranking, want := range map[string][]uuid.UUID{
+				"score":  {popular.ID, unanimous.ID},
+				"wilson": {unanimous.ID, popular.ID},
+				"hot":    {popular.ID, unanimous.ID},
+			} {
+				questions, err := query.GetRankedRoomQuestions(ctx, postgres.GetRankedRoomQuestionsParams{RoomID: room.ID, Ranking: ranking})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(questions) != 2 || questions[0].ID != want[0] || questions[1].ID != want[1] {
+					t.Fatalf("%s: unexpected order %+v", ranking, questions)
+				}
+			}
+
+			page, err := query.GetRankedRoomQuestions(ctx, postgres.GetRankedRoomQuestionsParams{
+				RoomID:     room.ID,
+				Ranking:    "score",
+				PageSize:   pgtype.Int4{Int32: 1, Valid: true},
+				PageOffset: 1,
+			})
+			if err != nil || len(page) != 1 || page[0].ID != unanimous.ID {
+				t.Fatalf("expected the second question, got %+v, %v", page, err)
+			}
+		}},
+		{"UpdateRoomRanking", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			updated, err := query.UpdateRoomRanking(ctx, postgres.UpdateRoomRankingParams{Ranking: "wilson", ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil || updated.Ranking != "wilson" {
+				t.Fatalf("expected the room ranked by wilson, got %+v, %v", updated, err)
+			}
+			if found, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil || found.Ranking != "wilson" {
+				t.Fatalf("expected the ranking to be stored, got %+v, %v", found, err)
+			}
+
+			if _, err := query.UpdateRoomRanking(ctx, postgres.UpdateRoomRankingParams{Ranking: "random", ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err == nil {
+				t.Fatal("expected an unknown ranking to be rejected")
+			}
+		}},
+		{"Comments", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+			other := mustCreateQuestion(t, query, room.ID, "Iterators?")
+
+			for _, text := range []string{"Especially for maps", "And slices"} {
+				if _, err := query.CreateComment(ctx, postgres.CreateCommentParams{QuestionID: question.ID, Text: text, AuthorHash: []byte("author")}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := query.CreateComment(ctx, postgres.CreateCommentParams{QuestionID: uuid.New(), Text: "orphan"}); err == nil {
+				t.Fatal("expected a foreign key violation for an unknown question")
+			}
+
+			comments, err := query.GetQuestionComments(ctx, question.ID)
+			if err != nil || len(comments) != 2 || comments[0].Text != "Especially for maps" {
+				t.Fatalf("expected the comments oldest first, got %+v, %v", comments, err)
+			}
+
+			counts, err := query.GetRoomCommentCounts(ctx, room.ID)
+			if err != nil || len(counts) != 1 || counts[0].QuestionID != question.ID || counts[0].CommentCount != 2 {
+				t.Fatalf("expected only the commented question, got %+v, %v", counts, err)
+			}
+
+			if deleted, err := query.DeleteComment(ctx, comments[0].ID); err != nil || deleted != 1 {
+				t.Fatalf("expected the comment to be deleted, got %d, %v", deleted, err)
+			}
+			if count, err := query.CountQuestionComments(ctx, question.ID); err != nil || count != 1 {
+				t.Fatalf("expected one comment left, got %d, %v", count, err)
+			}
+			if count, err := query.CountQuestionComments(ctx, other.ID); err != nil || count != 0 {
+				t.Fatalf("expected no comments, got %d, %v", count, err)
+			}
+
+			if _, err := query.DeleteQuestion(ctx, question.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := query.GetComment(ctx, comments[1].ID); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected the comments to go away with the question, got %v", err)
+			}
+		}},
+		{"SetRoomSpotlight and AddSpotlightTime", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			spotlighted, err := query.SetRoomSpotlight(ctx, postgres.SetRoomSpotlightParams{QuestionID: pgtype.UUID{Bytes: question.ID, Valid: true}, ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil || !spotlighted.SpotlightedAt.Valid || spotlighted.SpotlightQuestionID.Bytes != question.ID {
+				t.Fatalf("expected the question in the spotlight, got %+v, %v", spotlighted, err)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			if err := query.AddSpotlightTime(ctx, postgres.AddSpotlightTimeParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+			question, err = query.GetQuestion(ctx, question.ID)
+			if err != nil || question.SpotlightSeconds <= 0 {
+				t.Fatalf("expected the time in the spotlight to add up, got %v, %v", question.SpotlightSeconds, err)
+			}
+
+			cleared, err := query.SetRoomSpotlight(ctx, postgres.SetRoomSpotlightParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil || cleared.SpotlightQuestionID.Valid || cleared.SpotlightedAt.Valid {
+				t.Fatalf("expected no spotlight, got %+v, %v", cleared, err)
+			}
+
+			// Clearing an empty spotlight adds no time.
+			if err := query.AddSpotlightTime(ctx, postgres.AddSpotlightTimeParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+			if again, err := query.GetQuestion(ctx, question.ID); err != nil || again.SpotlightSeconds != question.SpotlightSeconds {
+				t.Fatalf("expected the time to stay, got %v, %v", again.SpotlightSeconds, err)
+			}
+
+			if _, err := query.SetRoomSpotlight(ctx, postgres.SetRoomSpotlightParams{QuestionID: pgtype.UUID{Bytes: question.ID, Valid: true}, ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := query.DeleteQuestion(ctx, question.ID); err != nil {
+				t.Fatal(err)
+			}
+			room, err = query.GetRoom(ctx, postgres.GetRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil || room.SpotlightQuestionID.Valid {
+				t.Fatalf("expected the purged question to leave the spotlight, got %+v, %v", room, err)
+			}
+		}},
+		{"PinQuestion and ReorderPinnedQuestions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			popular := mustCreateQuestion(t, query, room.ID, "Generics?")
+			first := mustCreateQuestion(t, query, room.ID, "Iterators?")
+			second := mustCreateQuestion(t, query, room.ID, "Modules?")
+
+			if _, err := query.ReactToQuestion(ctx, popular.ID); err != nil {
+				t.Fatal(err)
+			}
+			for _, question := range []postgres.Question{first, second, first} {
+				if _, err := query.PinQuestion(ctx, question.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ids, err := query.GetRoomPinnedQuestionIDs(ctx, room.ID)
+			if err != nil || len(ids) != 2 || ids[0] != first.ID || ids[1] != second.ID {
+				t.Fatalf("expected pinning again to keep the position, got %v, %v", ids, err)
+			}
+
+			if err := query.ReorderPinnedQuestions(ctx, postgres.ReorderPinnedQuestionsParams{QuestionIds: []uuid.UUID{second.ID, first.ID}, RoomID: room.ID}); err != nil {
+				t.Fatal(err)
+			}
+
+			questions, err := query.GetRankedRoomQuestions(ctx, postgres.GetRankedRoomQuestionsParams{RoomID: room.ID, Ranking: "score"})
+			if err != nil || len(questions) != 3 || questions[0].ID != second.ID || questions[1].ID != first.ID || questions[2].ID != popular.ID {
+				t.Fatalf("expected the pins first in their order, got %+v, %v", questions, err)
+			}
+
+			unpinned, err := query.UnpinQuestion(ctx, second.ID)
+			if err != nil || unpinned.PinnedAt.Valid || unpinned.PinPosition.Valid {
+				t.Fatalf("expected the question to be unpinned, got %+v, %v", unpinned, err)
+			}
+		}},
+		{"CreateQuestion with author name and UpdateRoomAnonymity", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			question, err := query.CreateQuestion(ctx, postgres.CreateQuestionParams{
+				RoomID:     room.ID,
+				Text:       "Generics?",
+				AuthorName: pgtype.Text{String: "Ana", Valid: true},
+			})
+			if err != nil || question.AuthorName.String != "Ana" {
+				t.Fatalf("expected the author name to be kept, got %+v, %v", question, err)
+			}
+
+			questions, err := query.GetRankedRoomQuestions(ctx, postgres.GetRankedRoomQuestionsParams{RoomID: room.ID, Ranking: "score"})
+			if err != nil || len(questions) != 1 || questions[0].AuthorName.String != "Ana" {
+				t.Fatalf("expected the listing to carry the author name, got %+v, %v", questions, err)
+			}
+
+			if room.Anonymous {
+				t.Fatal("expected rooms not to be anonymous by default")
+			}
+			updated, err := query.UpdateRoomAnonymity(ctx, postgres.UpdateRoomAnonymityParams{Anonymous: true, ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil || !updated.Anonymous {
+				t.Fatalf("expected the room to be anonymous, got %+v, %v", updated, err)
+			}
+		}},
+		{"Organizations and API keys", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			organization, err := query.CreateOrganization(ctx, postgres.CreateOrganizationParams{Name: "Platform", Slug: "platform"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found, err := query.GetOrganizationBySlug(ctx, "platform"); err != nil || found.ID != organization.ID {
+				t.Fatalf("expected the organization by its slug, got %+v, %v", found, err)
+			}
+			if found, err := query.GetOrganization(ctx, organization.ID); err != nil || found.Slug != "platform" || found.Name != "Platform" {
+				t.Fatalf("expected the organization by its ID, got %+v, %v", found, err)
+			}
+			if _, err := query.GetOrganization(ctx, uuid.New()); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows for an unknown organization, got %v", err)
+			}
+			if _, err := query.CreateOrganization(ctx, postgres.CreateOrganizationParams{Name: "Again", Slug: "platform"}); err == nil {
+				t.Fatal("expected a taken organization slug to be rejected")
+			}
+
+			// The default organization comes with the migrations.
+			organizations, err := query.GetOrganizations(ctx)
+			if err != nil || len(organizations) != 2 || organizations[0].ID != db.DefaultOrganizationID || organizations[1].ID != organization.ID {
+				t.Fatalf("expected the default organization and the new one, got %+v, %v", organizations, err)
+			}
+
+			params := roomParams("Platform AMA", "ama")
+			params.OrganizationID = organization.ID
+			room, err := query.CreateRoom(ctx, params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Slugs only have to be unique within an organization.
+			if _, err := query.CreateRoom(ctx, roomParams("Default AMA", "ama")); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected the room to be hidden from other organizations, got %v", err)
+			}
+			if found, err := query.GetRoomBySlug(ctx, postgres.GetRoomBySlugParams{Slug: "ama", OrganizationID: organization.ID}); err != nil || found.ID != room.ID {
+				t.Fatalf("expected the organization's room by its slug, got %+v, %v", found, err)
+			}
+			if rooms, err := query.GetRooms(ctx, organization.ID); err != nil || len(rooms) != 1 {
+				t.Fatalf("expected only the organization's room, got %+v, %v", rooms, err)
+			}
+
+			key, err := query.CreateAPIKey(ctx, postgres.CreateAPIKeyParams{
+				OrganizationID: organization.ID,
+				Name:           "bot",
+				KeyHash:        []byte("hash"),
+				Scopes:         []string{"rooms:write", "questions:moderate"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found, err := query.GetAPIKeyByHash(ctx, []byte("hash")); err != nil || found.ID != key.ID || len(found.Scopes) != 2 || found.LastUsedAt.Valid {
+				t.Fatalf("expected the unused key by its hash, got %+v, %v", found, err)
+			}
+
+			if err := query.TouchAPIKey(ctx, key.ID); err != nil {
+				t.Fatal(err)
+			}
+			keys, err := query.GetOrganizationAPIKeys(ctx, organization.ID)
+			if err != nil || len(keys) != 1 || !keys[0].LastUsedAt.Valid {
+				t.Fatalf("expected the key to have been used, got %+v, %v", keys, err)
+			}
+
+			if _, err := query.RevokeAPIKey(ctx, postgres.RevokeAPIKeyParams{ID: key.ID, OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected keys of other organizations to be out of reach, got %v", err)
+			}
+			revoked, err := query.RevokeAPIKey(ctx, postgres.RevokeAPIKeyParams{ID: key.ID, OrganizationID: organization.ID})
+			if err != nil || !revoked.RevokedAt.Valid {
+				t.Fatalf("expected the key to be revoked, got %+v, %v", revoked, err)
+			}
+			if _, err := query.GetAPIKeyByHash(ctx, []byte("hash")); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected revoked keys to be rejected, got %v", err)
+			}
+		}},
+		{"Polls", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			poll, err := query.CreatePoll(ctx, postgres.CreatePollParams{RoomID: room.ID, Question: "Tabs or spaces?"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := query.CreatePollOptions(ctx, postgres.CreatePollOptionsParams{PollID: poll.ID, Texts: []string{"Tabs", "Spaces"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			options, err := query.GetPollOptions(ctx, poll.ID)
+			if err != nil || len(options) != 2 || options[0].Text != "Tabs" || options[0].Position != 1 || options[1].Position != 2 {
+				t.Fatalf("expected the options in order, got %+v, %v", options, err)
+			}
+
+			for _, vote := range []struct {
+				participant string
+				option      uuid.UUID
+			}{{"alice", options[0].ID}, {"bob", options[0].ID}, {"alice", options[1].ID}} {
+				if err := query.VoteInPoll(ctx, postgres.VoteInPollParams{PollID: poll.ID, ParticipantHash: []byte(vote.participant), OptionID: vote.option}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			counts, err := query.GetRoomPollOptions(ctx, room.ID)
+			if err != nil || len(counts) != 2 || counts[0].VoteCount != 1 || counts[1].VoteCount != 1 {
+				t.Fatalf("expected a vote moved instead of added, got %+v, %v", counts, err)
+			}
+
+			editors, err := query.CreatePoll(ctx, postgres.CreatePollParams{RoomID: room.ID, Question: "Vim or Emacs?"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := query.CreatePollOptions(ctx, postgres.CreatePollOptionsParams{PollID: editors.ID, Texts: []string{"Vim", "Emacs"}}); err != nil {
+				t.Fatal(err)
+			}
+			editorOptions, err := query.GetPollOptions(ctx, editors.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, participant := range []string{"carol", "dave"} {
+				if err := query.VoteInPoll(ctx, postgres.VoteInPollParams{PollID: editors.ID, ParticipantHash: []byte(participant), OptionID: editorOptions[0].ID}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			polls, err := query.GetRoomPolls(ctx, room.ID)
+			if err != nil || len(polls) != 2 || polls[0].ID != poll.ID || polls[1].ID != editors.ID {
+				t.Fatalf("expected both polls in the order they were created, got %+v, %v", polls, err)
+			}
+
+			results := make(map[uuid.UUID][]int64)
+			counts, err = query.GetRoomPollOptions(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, count := range counts {
+				results[count.PollID] = append(results[count.PollID], count.VoteCount)
+			}
+			if !slices.Equal(results[poll.ID], []int64{1, 1}) || !slices.Equal(results[editors.ID], []int64{2, 0}) {
+				t.Fatalf("unexpected results %v", results)
+			}
+
+			closed, err := query.ClosePoll(ctx, poll.ID)
+			if err != nil || !closed.ClosedAt.Valid {
+				t.Fatalf("expected the poll to be closed, got %+v, %v", closed, err)
+			}
+			reopened, err := query.ReopenPoll(ctx, poll.ID)
+			if err != nil || reopened.ClosedAt.Valid {
+				t.Fatalf("expected the poll to be open, got %+v, %v", reopened, err)
+			}
+
+			if _, err := query.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := query.GetPoll(ctx, poll.ID); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected the poll to go away with its room, got %v", err)
+			}
+		}},
+		{"CloseRoom and ReopenRoom", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			closed, err := query.CloseRoom(ctx, postgres.CloseRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !closed.ClosedAt.Valid {
+				t.Fatal("expected closed_at to be set")
+			}
+
+			again, err := query.CloseRoom(ctx, postgres.CloseRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !again.ClosedAt.Time.Equal(closed.ClosedAt.Time) {
+				t.Fatal("expected closing twice to keep the first closed_at")
+			}
+
+			reopened, err := query.ReopenRoom(ctx, postgres.ReopenRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reopened.ClosedAt.Valid {
+				t.Fatal("expected closed_at to be cleared")
+			}
+		}},
+		{"DeleteRoom", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			deleted, err := query.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 {
+				t.Fatalf("expected 1 deleted room, got %d", deleted)
+			}
+
+			if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+			}
+
+			// Soft-deleted rooms can still be removed for good.
+			hidden := mustCreateRoom(t, query, "Hidden")
+			if _, err := query.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: hidden.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+				t.Fatal(err)
+			}
+			if deleted, err := query.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: hidden.ID, OrganizationID: db.DefaultOrganizationID}); err != nil || deleted != 1 {
+				t.Fatalf("expected the soft-deleted room to be deleted, got %d, %v", deleted, err)
+			}
+		}},
+		{"DeleteRoomQuestions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			other := mustCreateRoom(t, query, "Other")
+			mustCreateQuestion(t, query, room.ID, "First")
+			mustCreateQuestion(t, query, room.ID, "Second")
+			hidden := mustCreateQuestion(t, query, room.ID, "Hidden")
+			mustCreateQuestion(t, query, other.ID, "Elsewhere")
+
+			if _, err := query.SoftDeleteQuestion(ctx, hidden.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			// Soft-deleted questions are purged along with the rest.
+			deleted, err := query.DeleteRoomQuestions(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 3 {
+				t.Fatalf("expected 3 deleted questions, got %d", deleted)
+			}
+
+			remaining, err := query.GetRoomQuestions(ctx, other.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(remaining) != 1 {
+				t.Fatalf("expected the other room to keep its question, got %d", len(remaining))
+			}
+		}},
+		{"CreateQuestion", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			if question.RoomID != room.ID || question.Text != "Generics?" || question.ReactionCount != 0 || question.Answered {
+				t.Fatalf("unexpected question %+v", question)
+			}
+
+			_, err := query.CreateQuestion(ctx, postgres.CreateQuestionParams{RoomID: uuid.New(), Text: "Orphan"})
+			if err == nil {
+				t.Fatal("expected a foreign key violation for an unknown room")
+			}
+		}},
+		{"CreateQuestions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+
+			count, err := query.CreateQuestions(ctx, []postgres.CreateQuestionsParams{
+				{ID: uuid.New(), RoomID: room.ID, Text: "First", ReactionCount: 3},
+				{ID: uuid.New(), RoomID: room.ID, Text: "Second", Answered: true},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 2 {
+				t.Fatalf("expected 2 copied rows, got %d", count)
+			}
+
+			questions, err := query.GetRoomQuestionsByPopularity(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(questions) != 2 || questions[0].ReactionCount != 3 || !questions[1].Answered || !questions[1].CreatedAt.Valid {
+				t.Fatalf("unexpected questions %+v", questions)
+			}
+		}},
+		{"GetQuestion", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			created := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			question, err := query.GetQuestion(ctx, created.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if question.ID != created.ID || question.Text != created.Text {
+				t.Fatalf("expected %+v, got %+v", created, question)
+			}
+
+			if _, err := query.GetQuestion(ctx, uuid.New()); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+			}
+		}},
+		{"GetRoomQuestions", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			other := mustCreateRoom(t, query, "Other")
+			mustCreateQuestion(t, query, room.ID, "First")
+			mustCreateQuestion(t, query, room.ID, "Second")
+			mustCreateQuestion(t, query, other.ID, "Elsewhere")
+
+			questions, err := query.GetRoomQuestions(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(questions) != 2 {
+				t.Fatalf("expected 2 questions, got %d", len(questions))
+			}
+		}},
+		{"GetRoomQuestionsByPopularity", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			first := mustCreateQuestion(t, query, room.ID, "First")
+			second := mustCreateQuestion(t, query, room.ID, "Second")
+			popular := mustCreateQuestion(t, query, room.ID, "Popular")
+
+			if _, err := query.ReactToQuestion(ctx, popular.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			questions, err := query.GetRoomQuestionsByPopularity(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []uuid.UUID{popular.ID, first.ID, second.ID}
+			for index, question := range questions {
+				if question.ID != want[index] {
+					t.Fatalf("position %d: expected %s, got %s", index, want[index], question.ID)
+				}
+			}
+		}},
+		{"ReactToQuestion", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			for want := int64(1); want <= 3; want++ {
+				count, err := query.ReactToQuestion(ctx, question.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if count != want {
+					t.Fatalf("expected %d reactions, got %d", want, count)
+				}
+			}
+		}},
+		{"RemoveReactionFromQuestion", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			if _, err := query.ReactToQuestion(ctx, question.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			count, err := query.RemoveReactionFromQuestion(ctx, question.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 0 {
+				t.Fatalf("expected 0 reactions, got %d", count)
+			}
+		}},
+		{"MarkQuestionAsAnswered", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			if err := query.MarkQuestionAsAnswered(ctx, question.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			question, err := query.GetQuestion(ctx, question.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !question.Answered {
+				t.Fatal("expected the question to be answered")
+			}
+		}},
+		{"SoftDeleteRoom", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			mustCreateRoom(t, query, "Other")
+
+			deleted, err := query.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 {
+				t.Fatalf("expected 1 deleted room, got %d", deleted)
+			}
+
+			if _, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+			}
+
+			rooms, err := query.GetRooms(ctx, db.DefaultOrganizationID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rooms) != 1 {
+				t.Fatalf("expected 1 room, got %d", len(rooms))
+			}
+
+			if deleted, err := query.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil || deleted != 0 {
+				t.Fatalf("expected nothing to delete twice, got %d, %v", deleted, err)
+			}
+		}},
+		{"UpdateQuestionText", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Genrics?")
+
+			edited, err := query.UpdateQuestionText(ctx, postgres.UpdateQuestionTextParams{
+				Text:              "Generics?",
+				ID:                question.ID,
+				EditWindowSeconds: 60,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if edited.Text != "Generics?" || !edited.UpdatedAt.Time.After(question.UpdatedAt.Time) {
+				t.Fatalf("unexpected question %+v", edited)
+			}
+
+			if _, err := query.UpdateQuestionText(ctx, postgres.UpdateQuestionTextParams{Text: "Late", ID: question.ID}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows after the window, got %v", err)
+			}
+
+			if _, err := query.ReactToQuestion(ctx, question.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := query.UpdateQuestionText(ctx, postgres.UpdateQuestionTextParams{Text: "Reacted", ID: question.ID, EditWindowSeconds: 60}); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows after a reaction, got %v", err)
+			}
+		}},
+		{"CreateQuestionEdit and GetQuestionEdits", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			for _, text := range []string{"Genrics?", "Generic?"} {
+				if err := query.CreateQuestionEdit(ctx, postgres.CreateQuestionEditParams{QuestionID: question.ID, Text: text}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			edits, err := query.GetQuestionEdits(ctx, question.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(edits) != 2 || edits[0].Text != "Genrics?" || edits[1].Text != "Generic?" {
+				t.Fatalf("unexpected edits %+v", edits)
+			}
+		}},
+		{"updated_at trigger", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			if _, err := query.ReactToQuestion(ctx, question.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			reacted, err := query.GetQuestion(ctx, question.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reacted.UpdatedAt.Time.After(question.UpdatedAt.Time) {
+				t.Fatalf("expected updated_at to move past %v, got %v", question.UpdatedAt.Time, reacted.UpdatedAt.Time)
+			}
+
+			closed, err := query.CloseRoom(ctx, postgres.CloseRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := query.CloseRoom(ctx, postgres.CloseRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !again.UpdatedAt.Time.Equal(closed.UpdatedAt.Time) {
+				t.Fatal("expected an update that changes nothing to keep updated_at")
+			}
+		}},
+		{"SoftDeleteQuestion", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+			mustCreateQuestion(t, query, room.ID, "Iterators?")
+
+			deleted, err := query.SoftDeleteQuestion(ctx, question.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 {
+				t.Fatalf("expected 1 deleted question, got %d", deleted)
+			}
+
+			if _, err := query.GetQuestion(ctx, question.ID); !errors.Is(err, pgx.ErrNoRows) {
+				t.Fatalf("expected pgx.ErrNoRows, got %v", err)
+			}
+
+			questions, err := query.GetRoomQuestions(ctx, room.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(questions) != 1 {
+				t.Fatalf("expected 1 question, got %d", len(questions))
+			}
+
+			rooms, err := query.GetRoomsWithQuestionCount(ctx, postgres.GetRoomsWithQuestionCountParams{OrganizationID: db.DefaultOrganizationID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rooms[0].QuestionCount != 1 {
+				t.Fatalf("expected deleted questions not to be counted, got %d", rooms[0].QuestionCount)
+			}
+		}},
+		{"DeleteQuestion", func(t *testing.T, ctx context.Context, query *postgres.Queries) {
+			room := mustCreateRoom(t, query, "Go AMA")
+			question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+			deleted, err := query.DeleteQuestion(ctx, question.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != 1 {
+				t.Fatalf("expected 1 deleted question, got %d", deleted)
+			}
+
+			if deleted, err := query.DeleteQuestion(ctx, question.ID); err != nil || deleted != 0 {
+				t.Fatalf("expected nothing to delete twice, got %d, %v", deleted, err)
+			}
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, _ := newTestDatabase(t)
+			test.run(t, context.Background(), query)
+		})
+	}
+}
+
+func TestDeletingRoomCascadesToQuestions(t *testing.T) {
+	ctx := context.Background()
+	query, _ := newTestDatabase(t)
+
+	room := mustCreateRoom(t, query, "Go AMA")
+	question := mustCreateQuestion(t, query, room.ID, "Generics?")
+
+	if _, err := query.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := query.GetQuestion(ctx, question.ID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("expected the question to be deleted with its room, got %v", err)
+	}
+}
+
+func TestPoolStoreRollsBackFailedTransactions(t *testing.T) {
+	ctx := context.Background()
+	_, pool := newTestDatabase(t)
+	store := postgres.NewStore(pool)
+
+	failure := errors.New("boom")
+	err := store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := query.CreateRoom(ctx, roomParams("Discarded", "discarded")); err != nil {
+			return err
+		}
+
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+
+	rooms, err := store.GetRooms(ctx, db.DefaultOrganizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rooms) != 0 {
+		t.Fatalf("expected the room to be rolled back, got %d rooms", len(rooms))
+	}
+}
+
+func TestAuditTriggers(t *testing.T) {
+	ctx := context.Background()
+	_, pool := newTestDatabase(t)
+	store := postgres.NewStore(pool)
+
+	var room postgres.Room
+	err := store.ExecTx(ctx, func(query postgres.Querier) error {
+		if err := query.SetAuditActor(ctx, "owner"); err != nil {
+			return err
+		}
+
+		var err error
+		room, err = query.CreateRoom(ctx, roomParams("Go AMA", "go-ama"))
+		if err != nil {
+			return err
+		}
+
+		question, err := query.CreateQuestion(ctx, postgres.CreateQuestionParams{RoomID: room.ID, Text: "Generics?"})
+		if err != nil {
+			return err
+		}
+
+		if _, err := query.ReactToQuestion(ctx, question.ID); err != nil {
+			return err
+		}
+
+		return query.MarkQuestionAsAnswered(ctx, question.ID)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside of a transaction the actor set above no longer applies.
+	if _, err := store.CloseRoom(ctx, postgres.CloseRoomParams{ID: room.ID, OrganizationID: db.DefaultOrganizationID}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.GetRoomAuditLog(ctx, room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ entity, action, actor, change string }{
+		{"room", "insert", "owner", "name"},
+		{"question", "insert", "owner", "text"},
+		{"question", "update", "owner", "answered"},
+		{"room", "update", "system", "closed_at"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+
+	for index, want := range expected {
+		entry := entries[index]
+
+		var changes map[string]struct {
+			Old any `json:"old"`
+			New any `json:"new"`
+		}
+		if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.Entity != want.entity || entry.Action != want.action || entry.Actor != want.actor {
+			t.Fatalf("entry %d: expected %s %s by %s, got %+v", index, want.entity, want.action, want.actor, entry)
+		}
+		if _, ok := changes[want.change]; !ok {
+			t.Fatalf("entry %d: expected a change to %s, got %s", index, want.change, entry.Changes)
+		}
+		if _, ok := changes["owner_token_hash"]; ok {
+			t.Fatalf("entry %d leaks the owner token hash", index)
+		}
+	}
+}
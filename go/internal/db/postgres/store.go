@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolStore runs the generated queries against a connection pool.
+type PoolStore struct {
+	*Queries
+	pool *pgxpool.Pool
+}
+
+func NewStore(pool *pgxpool.Pool) *PoolStore {
+	return &PoolStore{Queries: New(pool), pool: pool}
+}
+
+func (store *PoolStore) ExecTx(ctx context.Context, fn func(query Querier) error) error {
+	tx, err := store.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(store.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
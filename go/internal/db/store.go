@@ -0,0 +1,26 @@
+// Package db describes the storage the application runs on. The sqlc generated
+// queries in the postgres package are the reference implementation, and the
+// memory package provides a database-free one for tests.
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// Store exposes every query in queries.sql plus a way to group several of
+// them into a single transaction.
+type Store interface {
+	postgres.Querier
+
+	// ExecTx runs fn against a Querier bound to a transaction, which is
+	// committed when fn returns nil and rolled back otherwise.
+	ExecTx(ctx context.Context, fn func(query postgres.Querier) error) error
+}
+
+// DefaultOrganizationID is the organization the migrations create for the
+// rooms that predate organizations. Requests that don't name an organization
+// act within it.
+var DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
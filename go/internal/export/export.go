@@ -0,0 +1,194 @@
+// Package export writes a room and its questions as JSON, CSV or a Markdown
+// transcript. Questions are expected in the order they should appear, which
+// is by popularity for every caller today.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+type Format string
+
+const (
+	JSON     Format = "json"
+	CSV      Format = "csv"
+	Markdown Format = "md"
+)
+
+// CSVHeader lists the columns of a CSV export, one row per question.
+var CSVHeader = []string{"room_id", "room_name", "question_id", "text", "reaction_count", "answered", "created_at", "updated_at", "spotlight_seconds"}
+
+func ParseFormat(raw string) (Format, error) {
+	switch format := Format(raw); format {
+	case JSON, CSV, Markdown:
+		return format, nil
+	case "":
+		return JSON, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q, expected json, csv or md", raw)
+	}
+}
+
+func (format Format) ContentType() string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Write encodes the room and its questions to writer, one question at a time.
+func Write(writer io.Writer, format Format, room postgres.Room, questions []postgres.Question) error {
+	switch format {
+	case CSV:
+		return writeCSV(writer, room, questions)
+	case Markdown:
+		return writeMarkdown(writer, room, questions)
+	default:
+		return writeJSON(writer, room, questions)
+	}
+}
+
+type Room struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	ClosedAt  string `json:"closed_at,omitempty"`
+}
+
+type Question struct {
+	ID            string `json:"id"`
+	Text          string `json:"text"`
+	ReactionCount int64  `json:"reaction_count"`
+	Answered      bool   `json:"answered"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	// SpotlightSeconds is how long the question was being answered.
+	SpotlightSeconds int64 `json:"spotlight_seconds"`
+}
+
+func newRoom(room postgres.Room) Room {
+	return Room{
+		ID:        room.ID.String(),
+		Name:      room.Name,
+		Status:    RoomStatus(room),
+		CreatedAt: formatTime(room.CreatedAt),
+		UpdatedAt: formatTime(room.UpdatedAt),
+		ClosedAt:  formatTime(room.ClosedAt),
+	}
+}
+
+func newQuestion(room postgres.Room, question postgres.Question) Question {
+	return Question{
+		ID:               question.ID.String(),
+		Text:             question.Text,
+		ReactionCount:    question.ReactionCount,
+		Answered:         question.Answered,
+		CreatedAt:        formatTime(question.CreatedAt),
+		UpdatedAt:        formatTime(question.UpdatedAt),
+		SpotlightSeconds: int64(spotlightTime(room, question).Seconds()),
+	}
+}
+
+// spotlightTime adds the time the question has spent in the spotlight so far
+// when it's still there to the time it spent there before.
+func spotlightTime(room postgres.Room, question postgres.Question) time.Duration {
+	spent := time.Duration(question.SpotlightSeconds * float64(time.Second))
+	if room.SpotlightQuestionID.Valid && room.SpotlightQuestionID.Bytes == question.ID && room.SpotlightedAt.Valid {
+		spent += max(time.Since(room.SpotlightedAt.Time), 0)
+	}
+
+	return spent
+}
+
+// writeJSON produces {"room": {...}, "questions": [...]}, encoding the
+// questions one by one instead of building the whole document in memory.
+func writeJSON(writer io.Writer, room postgres.Room, questions []postgres.Question) error {
+	encoder := json.NewEncoder(writer)
+
+	if _, err := io.WriteString(writer, `{"room":`); err != nil {
+		return err
+	}
+	if err := encoder.Encode(newRoom(room)); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, `,"questions":[`); err != nil {
+		return err
+	}
+
+	for index, question := range questions {
+		if index > 0 {
+			if _, err := io.WriteString(writer, ","); err != nil {
+				return err
+			}
+		}
+
+		if err := encoder.Encode(newQuestion(room, question)); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(writer, "]}\n")
+	return err
+}
+
+func writeCSV(writer io.Writer, room postgres.Room, questions []postgres.Question) error {
+	csvWriter := csv.NewWriter(writer)
+
+	if err := csvWriter.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	for _, question := range questions {
+		exported := newQuestion(room, question)
+
+		record := []string{
+			room.ID.String(),
+			room.Name,
+			exported.ID,
+			exported.Text,
+			strconv.FormatInt(exported.ReactionCount, 10),
+			strconv.FormatBool(exported.Answered),
+			exported.CreatedAt,
+			exported.UpdatedAt,
+			strconv.FormatInt(exported.SpotlightSeconds, 10),
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func RoomStatus(room postgres.Room) string {
+	if room.ClosedAt.Valid {
+		return "closed"
+	}
+
+	return "open"
+}
+
+// formatTime renders timestamps as RFC 3339 in UTC.
+func formatTime(timestamp pgtype.Timestamptz) string {
+	if !timestamp.Valid {
+		return ""
+	}
+
+	return timestamp.Time.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,142 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+func fixture() (postgres.Room, []postgres.Question) {
+	createdAt := pgtype.Timestamptz{Time: time.Date(2024, 8, 1, 18, 30, 0, 0, time.UTC), Valid: true}
+	room := postgres.Room{ID: uuid.New(), Name: "Go *AMA*", CreatedAt: createdAt, UpdatedAt: createdAt}
+
+	return room, []postgres.Question{
+		{ID: uuid.New(), RoomID: room.ID, Text: "Will generics get faster?", ReactionCount: 7, Answered: true, SpotlightSeconds: 125.4, CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: uuid.New(), RoomID: room.ID, Text: "Tabs, or spaces?", ReactionCount: 1, CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for raw, want := range map[string]Format{"": JSON, "json": JSON, "csv": CSV, "md": Markdown} {
+		got, err := ParseFormat(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseFormat(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	room, questions := fixture()
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, JSON, room, questions); err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Room      Room       `json:"room"`
+		Questions []Question `json:"questions"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buffer.String(), err)
+	}
+
+	if document.Room.Name != room.Name || document.Room.Status != "open" || document.Room.CreatedAt != "2024-08-01T18:30:00Z" {
+		t.Fatalf("unexpected room %+v", document.Room)
+	}
+	if len(document.Questions) != 2 || document.Questions[0].ReactionCount != 7 || !document.Questions[0].Answered || document.Questions[0].SpotlightSeconds != 125 {
+		t.Fatalf("unexpected questions %+v", document.Questions)
+	}
+}
+
+func TestWriteJSONCountsTheRunningSpotlight(t *testing.T) {
+	room, questions := fixture()
+	room.SpotlightQuestionID = pgtype.UUID{Bytes: questions[1].ID, Valid: true}
+	room.SpotlightedAt = pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, JSON, room, questions); err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Questions []Question `json:"questions"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buffer.String(), err)
+	}
+
+	if spent := document.Questions[1].SpotlightSeconds; spent < 60 || spent > 70 {
+		t.Fatalf("expected about a minute in the spotlight, got %d seconds", spent)
+	}
+}
+
+func TestWriteJSONWithoutQuestions(t *testing.T) {
+	room, _ := fixture()
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, JSON, room, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !json.Valid(buffer.Bytes()) || !strings.Contains(buffer.String(), `"questions":[]`) {
+		t.Fatalf("unexpected document %q", buffer.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	room, questions := fixture()
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, CSV, room, questions); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(CSVHeader, ",") {
+		t.Fatalf("unexpected header %v", records[0])
+	}
+	if records[1][3] != "Will generics get faster?" || records[1][4] != "7" || records[1][5] != "true" || records[1][8] != "125" {
+		t.Fatalf("unexpected row %v", records[1])
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	room, questions := fixture()
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, Markdown, room, questions); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript := buffer.String()
+	for _, want := range []string{
+		`# Go \*AMA\*`,
+		"2 questions · 1 answered · 1 unanswered",
+		"## Answered questions\n\n### 1. Will generics get faster?\n\n7 reactions",
+		"spotlighted for 2m5s\n",
+		"## Unanswered questions\n\n### 1. Tabs, or spaces?\n\n1 reaction ·",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Fatalf("expected transcript to contain %q:\n%s", want, transcript)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"#", `\#`,
+	"|", `\|`,
+	"\r\n", " ",
+	"\n", " ",
+)
+
+// writeMarkdown produces a transcript ready to be published: the answered
+// questions first, then the ones left open, each keeping the given order.
+func writeMarkdown(writer io.Writer, room postgres.Room, questions []postgres.Question) error {
+	buffered := bufio.NewWriter(writer)
+
+	var answered, unanswered []postgres.Question
+	for _, question := range questions {
+		if question.Answered {
+			answered = append(answered, question)
+		} else {
+			unanswered = append(unanswered, question)
+		}
+	}
+
+	fmt.Fprintf(buffered, "# %s\n\n", markdownEscaper.Replace(room.Name))
+	fmt.Fprintf(buffered, "%s · %d answered · %d unanswered\n", plural(len(questions), "question"), len(answered), len(unanswered))
+
+	writeMarkdownSection(buffered, room, "Answered questions", answered)
+	writeMarkdownSection(buffered, room, "Unanswered questions", unanswered)
+
+	return buffered.Flush()
+}
+
+func writeMarkdownSection(writer io.Writer, room postgres.Room, title string, questions []postgres.Question) {
+	if len(questions) == 0 {
+		return
+	}
+
+	fmt.Fprintf(writer, "\n## %s\n", title)
+
+	for index, question := range questions {
+		fmt.Fprintf(writer, "\n### %d. %s\n\n", index+1, markdownEscaper.Replace(question.Text))
+		fmt.Fprintf(writer, "%s · asked %s", plural(int(question.ReactionCount), "reaction"), formatTime(question.CreatedAt))
+		if spent := spotlightTime(room, question).Round(time.Second); spent > 0 {
+			fmt.Fprintf(writer, " · spotlighted for %s", spent)
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Row is a question read back from an export. Err is set when the row could
+// not be decoded, so callers can report every bad row at once.
+type Row struct {
+	Number        int
+	Text          string
+	ReactionCount int64
+	Answered      bool
+	Err           error
+}
+
+// Parse reads the questions of a JSON or CSV export. The room metadata is
+// ignored, and a CSV only needs a text column, so a plain spreadsheet of
+// questions works too.
+func Parse(reader io.Reader, format Format) ([]Row, error) {
+	switch format {
+	case JSON:
+		return parseJSON(reader)
+	case CSV:
+		return parseCSV(reader)
+	default:
+		return nil, fmt.Errorf("importing %s is not supported, use json or csv", format)
+	}
+}
+
+// parseJSON numbers rows by their position in the questions array.
+func parseJSON(reader io.Reader) ([]Row, error) {
+	var document struct {
+		Questions []json.RawMessage `json:"questions"`
+	}
+
+	if err := json.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JSON document: %w", err)
+	}
+
+	rows := make([]Row, 0, len(document.Questions))
+	for index, raw := range document.Questions {
+		var question struct {
+			Text          string `json:"text"`
+			ReactionCount int64  `json:"reaction_count"`
+			Answered      bool   `json:"answered"`
+		}
+
+		row := Row{Number: index + 1}
+		if err := json.Unmarshal(raw, &question); err != nil {
+			row.Err = errors.New("invalid question object")
+		} else {
+			row.Text = question.Text
+			row.ReactionCount = question.ReactionCount
+			row.Answered = question.Answered
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseCSV numbers rows the way a spreadsheet does, with the header on row 1.
+func parseCSV(reader io.Reader) ([]Row, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for index, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+
+	textColumn, ok := columns["text"]
+	if !ok {
+		return nil, errors.New(`the CSV header must have a "text" column`)
+	}
+
+	var rows []Row
+	for number := 2; ; number++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		row := Row{Number: number}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.Err = parseErr.Err
+			rows = append(rows, row)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row.Text = field(record, textColumn)
+
+		if index, ok := columns["reaction_count"]; ok && field(record, index) != "" {
+			if row.ReactionCount, err = strconv.ParseInt(field(record, index), 10, 64); err != nil {
+				row.Err = errors.New("reaction_count must be a whole number")
+			}
+		}
+
+		if index, ok := columns["answered"]; ok && field(record, index) != "" && row.Err == nil {
+			if row.Answered, err = strconv.ParseBool(field(record, index)); err != nil {
+				row.Err = errors.New("answered must be true or false")
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func field(record []string, index int) string {
+	if index >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[index])
+}
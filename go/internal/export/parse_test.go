@@ -0,0 +1,77 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	room, questions := fixture()
+
+	for _, format := range []Format{JSON, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := Write(&buffer, format, room, questions); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := Parse(&buffer, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(rows) != len(questions) {
+				t.Fatalf("expected %d rows, got %d", len(questions), len(rows))
+			}
+			for index, row := range rows {
+				question := questions[index]
+				if row.Err != nil || row.Text != question.Text || row.ReactionCount != question.ReactionCount || row.Answered != question.Answered {
+					t.Fatalf("row %d: expected %+v, got %+v", index, question, row)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVReportsBadRows(t *testing.T) {
+	document := "Text,Reaction_Count\nFirst,3\nSecond,many\nThird\n"
+
+	rows, err := Parse(strings.NewReader(document), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].Number != 2 || rows[0].Text != "First" || rows[0].ReactionCount != 3 || rows[0].Err != nil {
+		t.Fatalf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].Number != 3 || rows[1].Err == nil {
+		t.Fatalf("expected the second row to be rejected, got %+v", rows[1])
+	}
+	if rows[2].Text != "Third" || rows[2].Err != nil {
+		t.Fatalf("expected missing optional columns to be accepted, got %+v", rows[2])
+	}
+}
+
+func TestParseRejectsUnusableDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		document string
+	}{
+		{"CSV without a text column", CSV, "question\nWhy?\n"},
+		{"malformed JSON", JSON, `{"questions": [`},
+		{"markdown", Markdown, "# Room\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(test.document), test.format); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
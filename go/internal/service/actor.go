@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+	"github.com/pedrogiorgetti/ama/go/internal/auth"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// Actor identifies who performs an operation. The HTTP layer builds it from
+// the request headers.
+type Actor struct {
+	// OwnerToken is the secret handed out when the room was created.
+	OwnerToken string
+	// ParticipantID is an opaque identifier the client generates once and
+	// sends with every request, so a participant can be recognized without
+	// an account.
+	ParticipantID string
+	// JoinCode lets the actor into a private room.
+	JoinCode string
+	// APIKey is the key an integration authenticated with. It manages every
+	// room of its organization as if it owned them.
+	APIKey *postgres.ApiKey
+}
+
+func (actor Actor) owns(room postgres.Room) bool {
+	if actor.APIKey != nil && actor.APIKey.OrganizationID == room.OrganizationID {
+		return true
+	}
+
+	return auth.MatchToken(actor.OwnerToken, room.OwnerTokenHash)
+}
+
+func (actor Actor) authored(question postgres.Question) bool {
+	return auth.MatchToken(actor.ParticipantID, question.AuthorHash)
+}
+
+func (actor Actor) commented(comment postgres.Comment) bool {
+	return auth.MatchToken(actor.ParticipantID, comment.AuthorHash)
+}
+
+// participantHash is what gets stored to recognize the actor as an author
+// later, or nil when the actor didn't identify itself.
+func (actor Actor) participantHash() []byte {
+	if actor.ParticipantID == "" {
+		return nil
+	}
+
+	return auth.HashToken(actor.ParticipantID)
+}
+
+// auditName is how the audit log refers to the actor within the room. Neither
+// secret is ever written, participants are told apart by a short fingerprint
+// and integrations by the name of their API key.
+func (actor Actor) auditName(room postgres.Room) string {
+	switch {
+	case actor.APIKey != nil && actor.owns(room):
+		return "api-key:" + actor.APIKey.Name
+	case actor.owns(room):
+		return "owner"
+	case actor.ParticipantID != "":
+		return "participant:" + hex.EncodeToString(actor.participantHash()[:4])
+	default:
+		return "anonymous"
+	}
+}
+
+// setAuditActor attributes the changes the rest of the transaction makes to
+// the actor.
+func setAuditActor(ctx context.Context, query postgres.Querier, actor Actor, room postgres.Room) error {
+	return query.SetAuditActor(ctx, actor.auditName(room))
+}
+
+// getOwnedRoom loads a room and makes sure the actor owns it.
+func getOwnedRoom(ctx context.Context, query postgres.Querier, actor Actor, roomID uuid.UUID) (postgres.Room, error) {
+	room, err := getRoom(ctx, query, roomID)
+	if err != nil {
+		return postgres.Room{}, err
+	}
+
+	if !actor.owns(room) {
+		return postgres.Room{}, ErrForbidden
+	}
+
+	return room, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// GetComments lists the comments on a question, oldest first.
+func (service *QuestionService) GetComments(ctx context.Context, roomID, questionID uuid.UUID) ([]postgres.Comment, error) {
+	var comments []postgres.Comment
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		var err error
+		comments, err = query.GetQuestionComments(ctx, questionID)
+		return err
+	})
+
+	return comments, err
+}
+
+// CreateComment adds a follow-up comment to a question of an open room.
+func (service *QuestionService) CreateComment(ctx context.Context, actor Actor, roomID, questionID uuid.UUID, text string) (postgres.Comment, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return postgres.Comment{}, invalidInput("comment text is required")
+	}
+	if utf8.RuneCountInString(text) > maxTextLength {
+		return postgres.Comment{}, invalidInput("comment text must have at most %d characters", maxTextLength)
+	}
+
+	var comment postgres.Comment
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		comment, err = query.CreateComment(ctx, postgres.CreateCommentParams{
+			QuestionID: questionID,
+			Text:       text,
+			AuthorHash: actor.participantHash(),
+		})
+		return err
+	})
+
+	return comment, err
+}
+
+// DeleteComment removes a comment for good. Its author and the room owner can
+// do it.
+func (service *QuestionService) DeleteComment(ctx context.Context, actor Actor, roomID, questionID, commentID uuid.UUID) error {
+	return service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		comment, err := query.GetComment(ctx, commentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Like questions, comments can't be reached through another URL.
+		if comment.QuestionID != questionID {
+			return ErrCommentNotFound
+		}
+
+		if !actor.owns(room) && !actor.commented(comment) {
+			return ErrForbidden
+		}
+
+		_, err = query.DeleteComment(ctx, commentID)
+		return err
+	})
+}
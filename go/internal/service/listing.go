@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+const (
+	RoomSortNewest = "newest"
+	RoomSortActive = "active"
+
+	RoomStatusOpen   = "open"
+	RoomStatusClosed = "closed"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// RoomFilter selects and orders the rooms of a listing. The zero value lists
+// the newest rooms first, a page at a time.
+type RoomFilter struct {
+	// Search matches rooms whose name contains it, ignoring case.
+	Search string
+	// Status is empty, RoomStatusOpen or RoomStatusClosed.
+	Status string
+	// Sort is RoomSortNewest, the default, or RoomSortActive for the rooms
+	// with the most questions first.
+	Sort string
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	Limit  int
+}
+
+type RoomListing struct {
+	Room            postgres.Room
+	QuestionCount   int64
+	UnansweredCount int64
+}
+
+type RoomPage struct {
+	Rooms []RoomListing
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// ListRooms returns a page of the rooms matching the filter. Pages are cut
+// with keyset pagination, so rooms created in between don't shift them.
+// Only the default organization lists its rooms to anyone; the others only
+// list them to their own API keys.
+func (service *RoomService) ListRooms(ctx context.Context, actor Actor, filter RoomFilter) (RoomPage, error) {
+	if organizationID(ctx) != db.DefaultOrganizationID && actor.APIKey == nil {
+		return RoomPage{}, ErrForbidden
+	}
+
+	switch filter.Status {
+	case "", RoomStatusOpen, RoomStatusClosed:
+	default:
+		return RoomPage{}, invalidInput("status must be %q or %q", RoomStatusOpen, RoomStatusClosed)
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = RoomSortNewest
+	}
+	if filter.Sort != RoomSortNewest && filter.Sort != RoomSortActive {
+		return RoomPage{}, invalidInput("sort must be %q or %q", RoomSortNewest, RoomSortActive)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit < 1 || filter.Limit > maxPageSize {
+		return RoomPage{}, invalidInput("limit must be between 1 and %d", maxPageSize)
+	}
+
+	cursor, err := decodeRoomCursor(filter.Sort, filter.Cursor)
+	if err != nil {
+		return RoomPage{}, err
+	}
+
+	search := escapeLike(strings.TrimSpace(filter.Search))
+	// One extra row tells whether there is a next page.
+	pageSize := int32(filter.Limit + 1)
+
+	var listings []RoomListing
+	if filter.Sort == RoomSortActive {
+		rows, err := service.store.ListRoomsByActivity(ctx, postgres.ListRoomsByActivityParams{
+			OrganizationID:      organizationID(ctx),
+			Search:              search,
+			Status:              filter.Status,
+			After:               cursor.set,
+			CursorQuestionCount: cursor.questionCount,
+			CursorID:            cursor.id,
+			PageSize:            pageSize,
+		})
+		if err != nil {
+			return RoomPage{}, err
+		}
+
+		for _, row := range rows {
+			listings = append(listings, RoomListing{
+				Room:            row.Room,
+				QuestionCount:   row.QuestionCount,
+				UnansweredCount: row.UnansweredCount,
+			})
+		}
+	} else {
+		rows, err := service.store.ListRoomsByNewest(ctx, postgres.ListRoomsByNewestParams{
+			OrganizationID:  organizationID(ctx),
+			Search:          search,
+			Status:          filter.Status,
+			After:           cursor.set,
+			CursorCreatedAt: pgtype.Timestamptz{Time: cursor.createdAt, Valid: cursor.set},
+			CursorID:        cursor.id,
+			PageSize:        pageSize,
+		})
+		if err != nil {
+			return RoomPage{}, err
+		}
+
+		for _, row := range rows {
+			listings = append(listings, RoomListing{
+				Room:            row.Room,
+				QuestionCount:   row.QuestionCount,
+				UnansweredCount: row.UnansweredCount,
+			})
+		}
+	}
+
+	page := RoomPage{Rooms: listings}
+	if len(listings) > filter.Limit {
+		page.Rooms = listings[:filter.Limit]
+		page.NextCursor = encodeRoomCursor(filter.Sort, page.Rooms[filter.Limit-1])
+	}
+
+	return page, nil
+}
+
+// roomCursor is the position of the last room of a page in the listing order.
+type roomCursor struct {
+	set           bool
+	createdAt     time.Time
+	questionCount int64
+	id            uuid.UUID
+}
+
+// Cursors are opaque to clients: the sort they were made for, the sort key
+// and the room ID, base64 encoded.
+func encodeRoomCursor(sort string, listing RoomListing) string {
+	key := listing.Room.CreatedAt.Time.UTC().Format(time.RFC3339Nano)
+	if sort == RoomSortActive {
+		key = strconv.FormatInt(listing.QuestionCount, 10)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(sort + "|" + key + "|" + listing.Room.ID.String()))
+}
+
+func decodeRoomCursor(sort, encoded string) (roomCursor, error) {
+	if encoded == "" {
+		return roomCursor{}, nil
+	}
+
+	invalid := invalidInput("invalid cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return roomCursor{}, invalid
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != sort {
+		return roomCursor{}, invalid
+	}
+
+	cursor := roomCursor{set: true}
+	if cursor.id, err = uuid.Parse(parts[2]); err != nil {
+		return roomCursor{}, invalid
+	}
+
+	if sort == RoomSortActive {
+		cursor.questionCount, err = strconv.ParseInt(parts[1], 10, 64)
+	} else {
+		cursor.createdAt, err = time.Parse(time.RFC3339Nano, parts[1])
+	}
+	if err != nil {
+		return roomCursor{}, invalid
+	}
+
+	return cursor, nil
+}
+
+// escapeLike makes the wildcards of a search match literally in ILIKE.
+func escapeLike(search string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/auth"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// Every room belongs to an organization, and every operation runs within a
+// single one: rooms of other organizations can't be found, listed or changed.
+// The organization travels in the context, so the HTTP layer sets it once per
+// request with WithOrganization.
+
+type organizationContextKey struct{}
+
+// WithOrganization scopes the operations run with the returned context to the
+// rooms of the organization.
+func WithOrganization(ctx context.Context, organizationID uuid.UUID) context.Context {
+	return context.WithValue(ctx, organizationContextKey{}, organizationID)
+}
+
+// organizationID returns the organization the context is scoped to, which is
+// the default organization unless WithOrganization named another one.
+func organizationID(ctx context.Context) uuid.UUID {
+	if organizationID, ok := ctx.Value(organizationContextKey{}).(uuid.UUID); ok {
+		return organizationID
+	}
+
+	return db.DefaultOrganizationID
+}
+
+// Scopes of an API key. Read-only keys can't change anything, while the
+// others let the key manage the rooms, or moderate the questions, of every
+// room in its organization.
+const (
+	ScopeRoomsWrite        = "rooms:write"
+	ScopeQuestionsModerate = "questions:moderate"
+	ScopeReadOnly          = "read-only"
+)
+
+// ValidateScopes defaults the scopes of a new key to ScopeReadOnly alone,
+// which can't be combined with the others.
+func ValidateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return []string{ScopeReadOnly}, nil
+	}
+
+	for index, scope := range scopes {
+		switch scope {
+		case ScopeRoomsWrite, ScopeQuestionsModerate:
+		case ScopeReadOnly:
+			if len(scopes) > 1 {
+				return nil, invalidInput("scope %q can't be combined with others", ScopeReadOnly)
+			}
+		default:
+			return nil, invalidInput("scope must be %q, %q or %q", ScopeRoomsWrite, ScopeQuestionsModerate, ScopeReadOnly)
+		}
+		if slices.Contains(scopes[:index], scope) {
+			return nil, invalidInput("scope %q is repeated", scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// HasScope reports whether the key was granted the scope.
+func HasScope(apiKey postgres.ApiKey, scope string) bool {
+	return slices.Contains(apiKey.Scopes, scope)
+}
+
+type OrganizationService struct {
+	store db.Store
+}
+
+func NewOrganizationService(store db.Store) *OrganizationService {
+	return &OrganizationService{store: store}
+}
+
+// FindOrganization loads an organization by its ID or its slug.
+func (service *OrganizationService) FindOrganization(ctx context.Context, reference string) (postgres.Organization, error) {
+	var (
+		organization postgres.Organization
+		err          error
+	)
+	if organizationID, parseErr := uuid.Parse(reference); parseErr == nil {
+		organization, err = service.store.GetOrganization(ctx, organizationID)
+	} else {
+		organization, err = service.store.GetOrganizationBySlug(ctx, strings.ToLower(reference))
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return postgres.Organization{}, ErrOrganizationNotFound
+	}
+
+	return organization, err
+}
+
+// Authenticate finds the API key an integration presented, unless it was
+// revoked, and records that it was used.
+func (service *OrganizationService) Authenticate(ctx context.Context, key string) (postgres.ApiKey, error) {
+	if key == "" {
+		return postgres.ApiKey{}, ErrInvalidAPIKey
+	}
+
+	apiKey, err := service.store.GetAPIKeyByHash(ctx, auth.HashToken(key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return postgres.ApiKey{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return postgres.ApiKey{}, err
+	}
+
+	// The query only writes once a minute, so busy keys don't turn every
+	// request into a write.
+	if err := service.store.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		return postgres.ApiKey{}, err
+	}
+
+	return apiKey, nil
+}
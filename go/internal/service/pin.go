@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// PinQuestion pins the question to the top of the room, after the questions
+// pinned before it. Pinning a pinned question leaves it where it is. Only the
+// owner can pin questions.
+func (service *QuestionService) PinQuestion(ctx context.Context, actor Actor, roomID, questionID uuid.UUID) (QuestionDetails, error) {
+	return service.setPinned(ctx, actor, roomID, questionID, true)
+}
+
+// UnpinQuestion puts the question back among the ones ranked by votes.
+func (service *QuestionService) UnpinQuestion(ctx context.Context, actor Actor, roomID, questionID uuid.UUID) (QuestionDetails, error) {
+	return service.setPinned(ctx, actor, roomID, questionID, false)
+}
+
+func (service *QuestionService) setPinned(ctx context.Context, actor Actor, roomID, questionID uuid.UUID, pinned bool) (QuestionDetails, error) {
+	var details QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		var question postgres.Question
+		if pinned {
+			question, err = query.PinQuestion(ctx, questionID)
+		} else {
+			question, err = query.UnpinQuestion(ctx, questionID)
+		}
+		if err != nil {
+			return err
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// ReorderPinnedQuestions puts the pinned questions of the room in the order of
+// questionIDs, which has to list each of them exactly once.
+func (service *QuestionService) ReorderPinnedQuestions(ctx context.Context, actor Actor, roomID uuid.UUID, questionIDs []uuid.UUID) error {
+	return service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		pinned, err := query.GetRoomPinnedQuestionIDs(ctx, roomID)
+		if err != nil {
+			return err
+		}
+
+		if !samePins(pinned, questionIDs) {
+			return invalidInput("the order must list every pinned question of the room exactly once")
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		return query.ReorderPinnedQuestions(ctx, postgres.ReorderPinnedQuestionsParams{
+			QuestionIds: questionIDs,
+			RoomID:      roomID,
+		})
+	})
+}
+
+func samePins(pinned, ordered []uuid.UUID) bool {
+	if len(pinned) != len(ordered) {
+		return false
+	}
+
+	for index, id := range ordered {
+		if !slices.Contains(pinned, id) || slices.Contains(ordered[:index], id) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+const (
+	minPollOptions = 2
+	maxPollOptions = 10
+)
+
+type PollService struct {
+	store db.Store
+}
+
+func NewPollService(store db.Store) *PollService {
+	return &PollService{store: store}
+}
+
+// PollOption is an option of a poll along with the votes it got.
+type PollOption struct {
+	ID        uuid.UUID
+	Text      string
+	VoteCount int64
+}
+
+// PollDetails is a poll with its options in order.
+type PollDetails struct {
+	postgres.Poll
+	Options []PollOption
+}
+
+// CreatePoll opens a poll in the room. Only the owner can run polls.
+func (service *PollService) CreatePoll(ctx context.Context, actor Actor, roomID uuid.UUID, question string, options []string) (PollDetails, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return PollDetails{}, invalidInput("poll question is required")
+	}
+	if utf8.RuneCountInString(question) > maxTextLength {
+		return PollDetails{}, invalidInput("poll question must have at most %d characters", maxTextLength)
+	}
+
+	options, err := validatePollOptions(options)
+	if err != nil {
+		return PollDetails{}, err
+	}
+
+	var details PollDetails
+	err = service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		poll, err := query.CreatePoll(ctx, postgres.CreatePollParams{RoomID: roomID, Question: question})
+		if err != nil {
+			return err
+		}
+
+		if err := query.CreatePollOptions(ctx, postgres.CreatePollOptionsParams{PollID: poll.ID, Texts: options}); err != nil {
+			return err
+		}
+
+		details, err = getPollDetails(ctx, query, poll)
+		return err
+	})
+
+	return details, err
+}
+
+func validatePollOptions(options []string) ([]string, error) {
+	if len(options) < minPollOptions || len(options) > maxPollOptions {
+		return nil, invalidInput("a poll must have between %d and %d options", minPollOptions, maxPollOptions)
+	}
+
+	trimmed := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || utf8.RuneCountInString(option) > maxTextLength {
+			return nil, invalidInput("poll options must have between 1 and %d characters", maxTextLength)
+		}
+		if slices.Contains(trimmed, option) {
+			return nil, invalidInput("poll option %q is repeated", option)
+		}
+
+		trimmed = append(trimmed, option)
+	}
+
+	return trimmed, nil
+}
+
+// GetRoomPolls lists the polls of the room, oldest first, with their results.
+func (service *PollService) GetRoomPolls(ctx context.Context, roomID uuid.UUID) ([]PollDetails, error) {
+	var details []PollDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := getRoom(ctx, query, roomID); err != nil {
+			return err
+		}
+
+		polls, err := query.GetRoomPolls(ctx, roomID)
+		if err != nil {
+			return err
+		}
+
+		options, err := query.GetRoomPollOptions(ctx, roomID)
+		if err != nil {
+			return err
+		}
+
+		optionsByPoll := make(map[uuid.UUID][]PollOption)
+		for _, option := range options {
+			optionsByPoll[option.PollID] = append(optionsByPoll[option.PollID], PollOption{
+				ID:        option.ID,
+				Text:      option.Text,
+				VoteCount: option.VoteCount,
+			})
+		}
+
+		details = make([]PollDetails, 0, len(polls))
+		for _, poll := range polls {
+			details = append(details, PollDetails{Poll: poll, Options: optionsByPoll[poll.ID]})
+		}
+
+		return nil
+	})
+
+	return details, err
+}
+
+func (service *PollService) GetPoll(ctx context.Context, roomID, pollID uuid.UUID) (PollDetails, error) {
+	var details PollDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		poll, err := getRoomPoll(ctx, query, roomID, pollID)
+		if err != nil {
+			return err
+		}
+
+		details, err = getPollDetails(ctx, query, poll)
+		return err
+	})
+
+	return details, err
+}
+
+// ClosePoll stops the voting, keeping the results.
+func (service *PollService) ClosePoll(ctx context.Context, actor Actor, roomID, pollID uuid.UUID) (PollDetails, error) {
+	var details PollDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := getOwnedRoom(ctx, query, actor, roomID); err != nil {
+			return err
+		}
+
+		if _, err := getRoomPoll(ctx, query, roomID, pollID); err != nil {
+			return err
+		}
+
+		poll, err := query.ClosePoll(ctx, pollID)
+		if err != nil {
+			return err
+		}
+
+		details, err = getPollDetails(ctx, query, poll)
+		return err
+	})
+
+	return details, err
+}
+
+// ReopenPoll lets participants vote again in a closed poll of an open room.
+func (service *PollService) ReopenPoll(ctx context.Context, actor Actor, roomID, pollID uuid.UUID) (PollDetails, error) {
+	var details PollDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		if _, err := getRoomPoll(ctx, query, roomID, pollID); err != nil {
+			return err
+		}
+
+		poll, err := query.ReopenPoll(ctx, pollID)
+		if err != nil {
+			return err
+		}
+
+		details, err = getPollDetails(ctx, query, poll)
+		return err
+	})
+
+	return details, err
+}
+
+// Vote records the participant's vote for the option, replacing the vote they
+// cast before in the same poll. Voting requires a participant ID, which is what
+// keeps it to one vote per participant.
+func (service *PollService) Vote(ctx context.Context, actor Actor, roomID, pollID, optionID uuid.UUID) (PollDetails, error) {
+	participantHash := actor.participantHash()
+	if participantHash == nil {
+		return PollDetails{}, invalidInput("voting requires a participant ID")
+	}
+
+	var details PollDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		poll, err := getRoomPoll(ctx, query, roomID, pollID)
+		if err != nil {
+			return err
+		}
+
+		if poll.ClosedAt.Valid {
+			return ErrPollClosed
+		}
+
+		options, err := query.GetPollOptions(ctx, pollID)
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(options, func(option postgres.GetPollOptionsRow) bool { return option.ID == optionID }) {
+			return invalidInput("the option isn't one of the poll's")
+		}
+
+		if err := query.VoteInPoll(ctx, postgres.VoteInPollParams{
+			PollID:          pollID,
+			ParticipantHash: participantHash,
+			OptionID:        optionID,
+		}); err != nil {
+			return err
+		}
+
+		details, err = getPollDetails(ctx, query, poll)
+		return err
+	})
+
+	return details, err
+}
+
+// getRoomPoll is getRoomQuestion for polls.
+func getRoomPoll(ctx context.Context, query postgres.Querier, roomID, pollID uuid.UUID) (postgres.Poll, error) {
+	poll, err := query.GetPoll(ctx, pollID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return postgres.Poll{}, ErrPollNotFound
+	}
+	if err != nil {
+		return postgres.Poll{}, err
+	}
+
+	if poll.RoomID != roomID {
+		return postgres.Poll{}, ErrPollNotFound
+	}
+
+	return poll, nil
+}
+
+func getPollDetails(ctx context.Context, query postgres.Querier, poll postgres.Poll) (PollDetails, error) {
+	options, err := query.GetPollOptions(ctx, poll.ID)
+	if err != nil {
+		return PollDetails{}, err
+	}
+
+	details := PollDetails{Poll: poll, Options: make([]PollOption, 0, len(options))}
+	for _, option := range options {
+		details.Options = append(details.Options, PollOption{
+			ID:        option.ID,
+			Text:      option.Text,
+			VoteCount: option.VoteCount,
+		})
+	}
+
+	return details, nil
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+	"github.com/pedrogiorgetti/ama/go/internal/export"
+)
+
+// DefaultEditWindow is how long the author of a question can edit it when
+// nothing else is configured.
+const DefaultEditWindow = 5 * time.Minute
+
+type QuestionService struct {
+	store      db.Store
+	editWindow time.Duration
+}
+
+func NewQuestionService(store db.Store, editWindow time.Duration) *QuestionService {
+	return &QuestionService{store: store, editWindow: editWindow}
+}
+
+// CreateQuestion asks a question in an open room. The author can sign it with
+// a display name, which anonymous rooms drop.
+func (service *QuestionService) CreateQuestion(ctx context.Context, actor Actor, roomID uuid.UUID, text, authorName string) (QuestionDetails, error) {
+	text, err := validateQuestionText(text)
+	if err != nil {
+		return QuestionDetails{}, err
+	}
+
+	authorName = strings.TrimSpace(authorName)
+	if utf8.RuneCountInString(authorName) > maxAuthorNameLength {
+		return QuestionDetails{}, invalidInput("author name must have at most %d characters", maxAuthorNameLength)
+	}
+
+	var details QuestionDetails
+	err = service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		if room.Anonymous {
+			authorName = ""
+		}
+
+		question, err := query.CreateQuestion(ctx, postgres.CreateQuestionParams{
+			RoomID:     roomID,
+			Text:       text,
+			AuthorHash: actor.participantHash(),
+			AuthorName: pgtype.Text{String: authorName, Valid: authorName != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		details = QuestionDetails{Question: question, Reactions: newReactions(room, nil)}
+		return nil
+	})
+
+	return details, err
+}
+
+// EditQuestion replaces the text of a question. Only its author can do it, and
+// only within the edit window and before anyone reacted to or answered it. The
+// previous text is kept in the question's edit history.
+func (service *QuestionService) EditQuestion(ctx context.Context, actor Actor, roomID, questionID uuid.UUID, text string) (QuestionDetails, error) {
+	text, err := validateQuestionText(text)
+	if err != nil {
+		return QuestionDetails{}, err
+	}
+
+	var details QuestionDetails
+	err = service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		previous, err := getRoomQuestion(ctx, query, roomID, questionID)
+		if err != nil {
+			return err
+		}
+
+		if !actor.authored(previous) {
+			return ErrForbidden
+		}
+
+		// The conditions are checked again by the update itself, so a reaction
+		// landing in between can't be overwritten.
+		question, err := query.UpdateQuestionText(ctx, postgres.UpdateQuestionTextParams{
+			Text:              text,
+			ID:                questionID,
+			EditWindowSeconds: service.editWindow.Seconds(),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrQuestionLocked
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := query.CreateQuestionEdit(ctx, postgres.CreateQuestionEditParams{QuestionID: questionID, Text: previous.Text}); err != nil {
+			return err
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// GetQuestionEdits lists the previous texts of a question, oldest first.
+func (service *QuestionService) GetQuestionEdits(ctx context.Context, roomID, questionID uuid.UUID) ([]postgres.QuestionEdit, error) {
+	var edits []postgres.QuestionEdit
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		var err error
+		edits, err = query.GetQuestionEdits(ctx, questionID)
+		return err
+	})
+
+	return edits, err
+}
+
+func validateQuestionText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", invalidInput("question text is required")
+	}
+	if utf8.RuneCountInString(text) > maxTextLength {
+		return "", invalidInput("question text must have at most %d characters", maxTextLength)
+	}
+
+	return text, nil
+}
+
+// maxAuthorNameLength matches the size of the question.author_name column.
+const maxAuthorNameLength = 64
+
+// showAuthor leaves the author name of the question out when the room is
+// anonymous, including the names given before the owner made it so.
+func showAuthor(room postgres.Room, question postgres.Question) postgres.Question {
+	if room.Anonymous {
+		question.AuthorName = pgtype.Text{}
+	}
+
+	return question
+}
+
+// maxImportRows bounds how many questions a single import can create.
+const maxImportRows = 5000
+
+// Import validates every row and, only when all of them are valid, creates the
+// questions in a single bulk insert. It returns how many were created. Only
+// the owner can import questions, since they come with their counts.
+func (service *QuestionService) Import(ctx context.Context, actor Actor, roomID uuid.UUID, rows []export.Row) (int64, error) {
+	if len(rows) == 0 {
+		return 0, invalidInput("there are no questions to import")
+	}
+	if len(rows) > maxImportRows {
+		return 0, invalidInput("an import can have at most %d questions", maxImportRows)
+	}
+
+	params := make([]postgres.CreateQuestionsParams, 0, len(rows))
+	var rowErrors []RowError
+
+	for _, row := range rows {
+		text := strings.TrimSpace(row.Text)
+
+		switch {
+		case row.Err != nil:
+			rowErrors = append(rowErrors, RowError{Row: row.Number, Message: row.Err.Error()})
+		case text == "":
+			rowErrors = append(rowErrors, RowError{Row: row.Number, Message: "question text is required"})
+		case utf8.RuneCountInString(text) > maxTextLength:
+			rowErrors = append(rowErrors, RowError{Row: row.Number, Message: fmt.Sprintf("question text must have at most %d characters", maxTextLength)})
+		case row.ReactionCount < 0:
+			rowErrors = append(rowErrors, RowError{Row: row.Number, Message: "reaction_count can't be negative"})
+		default:
+			params = append(params, postgres.CreateQuestionsParams{
+				ID:            uuid.New(),
+				RoomID:        roomID,
+				Text:          text,
+				ReactionCount: row.ReactionCount,
+				Answered:      row.Answered,
+			})
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return 0, &ImportError{Rows: rowErrors}
+	}
+
+	var imported int64
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		if imported, err = query.CreateQuestions(ctx, params); err != nil {
+			return err
+		}
+
+		// Imported reaction counts are of the primary kind.
+		return query.CreateQuestionReactionsFromCounts(ctx, postgres.CreateQuestionReactionsFromCountsParams{
+			Kind:   room.PrimaryReaction,
+			RoomID: roomID,
+		})
+	})
+
+	return imported, err
+}
+
+// GetRoomQuestions lists the questions of a room in the order of its ranking.
+// A limit of zero lists them all from the offset on.
+func (service *QuestionService) GetRoomQuestions(ctx context.Context, roomID uuid.UUID, limit, offset int) ([]QuestionDetails, error) {
+	if limit < 0 || limit > maxPageSize {
+		return nil, invalidInput("limit must be between 0 and %d", maxPageSize)
+	}
+	if offset < 0 {
+		return nil, invalidInput("offset can't be negative")
+	}
+
+	var details []QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		questions, err := query.GetRankedRoomQuestions(ctx, postgres.GetRankedRoomQuestionsParams{
+			RoomID:     roomID,
+			Ranking:    room.Ranking,
+			PageSize:   pgtype.Int4{Int32: int32(limit), Valid: limit > 0},
+			PageOffset: int32(offset),
+		})
+		if err != nil {
+			return err
+		}
+
+		details, err = getRoomQuestionDetails(ctx, query, room, questions)
+		return err
+	})
+
+	return details, err
+}
+
+// SearchQuestions finds the questions of a room matching a web search style
+// query, best matches first, each with a highlighted snippet.
+func (service *QuestionService) SearchQuestions(ctx context.Context, roomID uuid.UUID, search string, limit int) ([]postgres.SearchRoomQuestionsRow, error) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return nil, invalidInput("search query is required")
+	}
+	if utf8.RuneCountInString(search) > maxTextLength {
+		return nil, invalidInput("search query must have at most %d characters", maxTextLength)
+	}
+
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+	if limit < 1 || limit > maxPageSize {
+		return nil, invalidInput("limit must be between 1 and %d", maxPageSize)
+	}
+
+	var rows []postgres.SearchRoomQuestionsRow
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := getRoom(ctx, query, roomID); err != nil {
+			return err
+		}
+
+		var err error
+		rows, err = query.SearchRoomQuestions(ctx, postgres.SearchRoomQuestionsParams{
+			Query:    search,
+			RoomID:   roomID,
+			PageSize: int32(limit),
+		})
+		return err
+	})
+
+	return rows, err
+}
+
+func (service *QuestionService) GetQuestion(ctx context.Context, roomID, questionID uuid.UUID) (QuestionDetails, error) {
+	var details QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		question, err := getRoomQuestion(ctx, query, roomID, questionID)
+		if err != nil {
+			return err
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// MarkAsAnswered marks the question as answered. Only the owner can do it.
+func (service *QuestionService) MarkAsAnswered(ctx context.Context, actor Actor, roomID, questionID uuid.UUID) error {
+	return service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		return query.MarkQuestionAsAnswered(ctx, questionID)
+	})
+}
+
+// DeleteQuestion hides the question, or removes it for good when purge is set.
+// Only the room owner can delete questions.
+func (service *QuestionService) DeleteQuestion(ctx context.Context, actor Actor, roomID, questionID uuid.UUID, purge bool) error {
+	return service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		// A hidden question can't stay in the spotlight.
+		if spotlights(room, questionID) {
+			if _, err := setSpotlight(ctx, query, roomID, pgtype.UUID{}); err != nil {
+				return err
+			}
+		}
+
+		if purge {
+			_, err = query.DeleteQuestion(ctx, questionID)
+			return err
+		}
+
+		_, err = query.SoftDeleteQuestion(ctx, questionID)
+		return err
+	})
+}
+
+// getRoomQuestion loads a question and makes sure it belongs to the given
+// room, so a question can't be reached through another room's URL.
+func getRoomQuestion(ctx context.Context, query postgres.Querier, roomID, questionID uuid.UUID) (postgres.Question, error) {
+	question, err := query.GetQuestion(ctx, questionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return postgres.Question{}, ErrQuestionNotFound
+	}
+	if err != nil {
+		return postgres.Question{}, err
+	}
+
+	if question.RoomID != roomID {
+		return postgres.Question{}, ErrQuestionNotFound
+	}
+
+	return question, nil
+}
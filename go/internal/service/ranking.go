@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// Rankings order the questions of a room, best first. Upvotes are the
+// reactions of the room's primary kind.
+const (
+	// RankingScore ranks by upvotes minus downvotes.
+	RankingScore = "score"
+	// RankingWilson ranks by the lower bound of the Wilson confidence
+	// interval for the share of upvotes, so a handful of votes doesn't beat
+	// many votes with a similar ratio.
+	RankingWilson = "wilson"
+	// RankingHot ranks by score decayed by the age of the question, so recent
+	// questions get a chance to rise.
+	RankingHot = "hot"
+)
+
+func validateRanking(ranking string) (string, error) {
+	switch ranking {
+	case "":
+		return RankingScore, nil
+	case RankingScore, RankingWilson, RankingHot:
+		return ranking, nil
+	default:
+		return "", invalidInput("ranking must be %q, %q or %q", RankingScore, RankingWilson, RankingHot)
+	}
+}
+
+// Downvote adds a downvote to the question and returns it with its updated
+// counts.
+func (service *QuestionService) Downvote(ctx context.Context, roomID, questionID uuid.UUID) (QuestionDetails, error) {
+	var details QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		question, err := getRoomQuestion(ctx, query, roomID, questionID)
+		if err != nil {
+			return err
+		}
+
+		if question.DownvoteCount, err = query.DownvoteQuestion(ctx, questionID); err != nil {
+			return err
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// RemoveDownvote takes a downvote back from the question. The count never goes
+// below zero.
+func (service *QuestionService) RemoveDownvote(ctx context.Context, roomID, questionID uuid.UUID) (QuestionDetails, error) {
+	var details QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		question, err := getRoomQuestion(ctx, query, roomID, questionID)
+		if err != nil {
+			return err
+		}
+
+		downvoteCount, err := query.RemoveDownvoteFromQuestion(ctx, questionID)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// There was no downvote to take back.
+		case err != nil:
+			return err
+		default:
+			question.DownvoteCount = downvoteCount
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// UpdateRanking changes how the questions of the room are ranked. Only the
+// owner can do it.
+func (service *RoomService) UpdateRanking(ctx context.Context, actor Actor, roomID uuid.UUID, ranking string) (postgres.Room, error) {
+	ranking, err := validateRanking(ranking)
+	if err != nil {
+		return postgres.Room{}, err
+	}
+
+	var room postgres.Room
+	err = service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		previous, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, previous); err != nil {
+			return err
+		}
+
+		room, err = query.UpdateRoomRanking(ctx, postgres.UpdateRoomRankingParams{
+			Ranking:        ranking,
+			ID:             roomID,
+			OrganizationID: previous.OrganizationID,
+		})
+		return err
+	})
+
+	return room, err
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// DefaultReactionKind is the only kind of reaction a room offers unless it's
+// configured otherwise.
+const DefaultReactionKind = "upvote"
+
+const (
+	maxReactionKinds      = 10
+	maxReactionKindLength = 32
+)
+
+// Reactions counts the reactions to a question by kind, with every kind the
+// room offers present.
+type Reactions map[string]int64
+
+// QuestionDetails is a question along with what's known about it beyond its
+// own row.
+type QuestionDetails struct {
+	postgres.Question
+	Reactions    Reactions
+	CommentCount int64
+}
+
+// validateReactionKinds defaults the kinds to DefaultReactionKind and the
+// primary kind to the first one. Kinds are short words or emoji without spaces,
+// such as "upvote", "me-too" or "🎉".
+func validateReactionKinds(kinds []string, primary string) ([]string, string, error) {
+	if len(kinds) == 0 {
+		kinds = []string{DefaultReactionKind}
+	}
+	if len(kinds) > maxReactionKinds {
+		return nil, "", invalidInput("a room can have at most %d reaction kinds", maxReactionKinds)
+	}
+
+	for index, kind := range kinds {
+		if kind == "" || len(kind) > maxReactionKindLength || strings.IndexFunc(kind, unicode.IsSpace) >= 0 {
+			return nil, "", invalidInput("reaction kinds must have between 1 and %d characters and no spaces", maxReactionKindLength)
+		}
+		if slices.Contains(kinds[:index], kind) {
+			return nil, "", invalidInput("reaction kind %q is repeated", kind)
+		}
+	}
+
+	if primary == "" {
+		primary = kinds[0]
+	}
+	if !slices.Contains(kinds, primary) {
+		return nil, "", invalidInput("primary reaction %q must be one of the reaction kinds", primary)
+	}
+
+	return kinds, primary, nil
+}
+
+// reactionKind defaults the kind to the primary one of the room, making sure
+// the room offers it.
+func reactionKind(room postgres.Room, kind string) (string, error) {
+	if kind == "" {
+		return room.PrimaryReaction, nil
+	}
+	if !slices.Contains(room.ReactionKinds, kind) {
+		return "", invalidInput("reaction kind must be one of %s", strings.Join(room.ReactionKinds, ", "))
+	}
+
+	return kind, nil
+}
+
+// newReactions leaves out the counts of kinds the room no longer offers.
+func newReactions(room postgres.Room, counts []postgres.QuestionReaction) Reactions {
+	reactions := make(Reactions, len(room.ReactionKinds))
+	for _, kind := range room.ReactionKinds {
+		reactions[kind] = 0
+	}
+
+	for _, count := range counts {
+		if _, ok := reactions[count.Kind]; ok {
+			reactions[count.Kind] = count.Count
+		}
+	}
+
+	return reactions
+}
+
+func getQuestionDetails(ctx context.Context, query postgres.Querier, room postgres.Room, question postgres.Question) (QuestionDetails, error) {
+	counts, err := query.GetQuestionReactions(ctx, question.ID)
+	if err != nil {
+		return QuestionDetails{}, err
+	}
+
+	commentCount, err := query.CountQuestionComments(ctx, question.ID)
+	if err != nil {
+		return QuestionDetails{}, err
+	}
+
+	return QuestionDetails{
+		Question:     showAuthor(room, question),
+		Reactions:    newReactions(room, counts),
+		CommentCount: commentCount,
+	}, nil
+}
+
+// getRoomQuestionDetails is getQuestionDetails for every question of the room
+// at once.
+func getRoomQuestionDetails(ctx context.Context, query postgres.Querier, room postgres.Room, questions []postgres.Question) ([]QuestionDetails, error) {
+	counts, err := query.GetRoomQuestionReactions(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	countsByQuestion := make(map[uuid.UUID][]postgres.QuestionReaction)
+	for _, count := range counts {
+		countsByQuestion[count.QuestionID] = append(countsByQuestion[count.QuestionID], count)
+	}
+
+	commentCounts, err := query.GetRoomCommentCounts(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	commentsByQuestion := make(map[uuid.UUID]int64, len(commentCounts))
+	for _, count := range commentCounts {
+		commentsByQuestion[count.QuestionID] = count.CommentCount
+	}
+
+	details := make([]QuestionDetails, 0, len(questions))
+	for _, question := range questions {
+		details = append(details, QuestionDetails{
+			Question:     showAuthor(room, question),
+			Reactions:    newReactions(room, countsByQuestion[question.ID]),
+			CommentCount: commentsByQuestion[question.ID],
+		})
+	}
+
+	return details, nil
+}
+
+// React adds a reaction of the given kind, or of the room's primary kind when
+// it's empty, and returns the question with its updated counts. Reactions of
+// the primary kind also count towards the question's reaction_count, which
+// ranks the questions.
+func (service *QuestionService) React(ctx context.Context, roomID, questionID uuid.UUID, kind string) (QuestionDetails, error) {
+	var details QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		question, err := getRoomQuestion(ctx, query, roomID, questionID)
+		if err != nil {
+			return err
+		}
+
+		if kind, err = reactionKind(room, kind); err != nil {
+			return err
+		}
+
+		if _, err := query.AddQuestionReaction(ctx, postgres.AddQuestionReactionParams{QuestionID: questionID, Kind: kind}); err != nil {
+			return err
+		}
+
+		if kind == room.PrimaryReaction {
+			if question.ReactionCount, err = query.ReactToQuestion(ctx, questionID); err != nil {
+				return err
+			}
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// RemoveReaction takes a reaction back from the question, the way React adds
+// it. Counts never go below zero.
+func (service *QuestionService) RemoveReaction(ctx context.Context, roomID, questionID uuid.UUID, kind string) (QuestionDetails, error) {
+	var details QuestionDetails
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getRoom(ctx, query, roomID)
+		if err != nil {
+			return err
+		}
+
+		question, err := getRoomQuestion(ctx, query, roomID, questionID)
+		if err != nil {
+			return err
+		}
+
+		if kind, err = reactionKind(room, kind); err != nil {
+			return err
+		}
+
+		_, err = query.RemoveQuestionReaction(ctx, postgres.RemoveQuestionReactionParams{QuestionID: questionID, Kind: kind})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// There was no reaction of that kind to take back.
+		case err != nil:
+			return err
+		case kind == room.PrimaryReaction:
+			if question.ReactionCount, err = query.RemoveReactionFromQuestion(ctx, questionID); err != nil {
+				return err
+			}
+		}
+
+		details, err = getQuestionDetails(ctx, query, room, question)
+		return err
+	})
+
+	return details, err
+}
+
+// UpdateReactions changes the kinds of reactions the room offers. Switching
+// the primary kind ranks the questions by the counts of the new one. Only the
+// owner can do it.
+func (service *RoomService) UpdateReactions(ctx context.Context, actor Actor, roomID uuid.UUID, kinds []string, primary string) (postgres.Room, error) {
+	kinds, primary, err := validateReactionKinds(kinds, primary)
+	if err != nil {
+		return postgres.Room{}, err
+	}
+
+	var room postgres.Room
+	err = service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		previous, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, previous); err != nil {
+			return err
+		}
+
+		room, err = query.UpdateRoomReactions(ctx, postgres.UpdateRoomReactionsParams{
+			ReactionKinds:   kinds,
+			PrimaryReaction: primary,
+			ID:              roomID,
+			OrganizationID:  previous.OrganizationID,
+		})
+		if err != nil || primary == previous.PrimaryReaction {
+			return err
+		}
+
+		return query.SetQuestionReactionCounts(ctx, postgres.SetQuestionReactionCountsParams{Kind: primary, RoomID: roomID})
+	})
+
+	return room, err
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/auth"
+	"github.com/pedrogiorgetti/ama/go/internal/db"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// Visibilities of a room. Public rooms are listed, unlisted rooms are only
+// reachable by their ID and private rooms also require the join code or a
+// grant from having entered it before.
+const (
+	RoomVisibilityPublic   = "public"
+	RoomVisibilityUnlisted = "unlisted"
+	RoomVisibilityPrivate  = "private"
+)
+
+type RoomService struct {
+	store db.Store
+}
+
+func NewRoomService(store db.Store) *RoomService {
+	return &RoomService{store: store}
+}
+
+// RoomSettings describes the room to create.
+type RoomSettings struct {
+	Name string
+	// Visibility defaults to RoomVisibilityPublic.
+	Visibility string
+	// Slug is generated from the name when it's left empty.
+	Slug string
+	// ReactionKinds default to DefaultReactionKind alone and PrimaryReaction,
+	// which ranks the questions, to the first of them.
+	ReactionKinds   []string
+	PrimaryReaction string
+	// Ranking defaults to RankingScore.
+	Ranking string
+	// Anonymous rooms drop the display names of question authors.
+	Anonymous bool
+}
+
+// CreateRoom creates a room and returns it with its owner token, which is
+// only known at this point since the room keeps just its hash. Private rooms
+// and the rooms of organizations other than the default one get a join code.
+func (service *RoomService) CreateRoom(ctx context.Context, settings RoomSettings) (postgres.Room, string, error) {
+	name := strings.TrimSpace(settings.Name)
+	if name == "" {
+		return postgres.Room{}, "", invalidInput("room name is required")
+	}
+	if utf8.RuneCountInString(name) > maxTextLength {
+		return postgres.Room{}, "", invalidInput("room name must have at most %d characters", maxTextLength)
+	}
+
+	slug := strings.TrimSpace(settings.Slug)
+	if slug != "" {
+		if err := ValidateSlug(slug); err != nil {
+			return postgres.Room{}, "", err
+		}
+	}
+
+	visibility := settings.Visibility
+	switch visibility {
+	case "":
+		visibility = RoomVisibilityPublic
+	case RoomVisibilityPublic, RoomVisibilityUnlisted, RoomVisibilityPrivate:
+	default:
+		return postgres.Room{}, "", invalidInput("visibility must be %q, %q or %q", RoomVisibilityPublic, RoomVisibilityUnlisted, RoomVisibilityPrivate)
+	}
+
+	var joinCode pgtype.Text
+	if requiresJoinCode(visibility, organizationID(ctx)) {
+		code, err := auth.NewJoinCode()
+		if err != nil {
+			return postgres.Room{}, "", err
+		}
+		joinCode = pgtype.Text{String: code, Valid: true}
+	}
+
+	reactionKinds, primaryReaction, err := validateReactionKinds(settings.ReactionKinds, settings.PrimaryReaction)
+	if err != nil {
+		return postgres.Room{}, "", err
+	}
+
+	ranking, err := validateRanking(settings.Ranking)
+	if err != nil {
+		return postgres.Room{}, "", err
+	}
+
+	ownerToken, err := auth.NewToken()
+	if err != nil {
+		return postgres.Room{}, "", err
+	}
+
+	params := postgres.CreateRoomParams{
+		Name:            name,
+		OwnerTokenHash:  auth.HashToken(ownerToken),
+		Visibility:      visibility,
+		JoinCode:        joinCode,
+		Slug:            slug,
+		ReactionKinds:   reactionKinds,
+		PrimaryReaction: primaryReaction,
+		Ranking:         ranking,
+		Anonymous:       settings.Anonymous,
+		OrganizationID:  organizationID(ctx),
+	}
+
+	// A generated slug that happens to be taken is simply generated again,
+	// each attempt in its own transaction since the failed insert aborts it.
+	var room postgres.Room
+	for attempt := 1; ; attempt++ {
+		if slug == "" {
+			if params.Slug, err = NewSlug(name); err != nil {
+				return postgres.Room{}, "", err
+			}
+		}
+
+		err = service.store.ExecTx(ctx, func(query postgres.Querier) error {
+			// Whoever creates a room owns it.
+			if err := query.SetAuditActor(ctx, "owner"); err != nil {
+				return err
+			}
+
+			room, err = query.CreateRoom(ctx, params)
+			return err
+		})
+		if !isSlugTaken(err) {
+			break
+		}
+		if slug != "" {
+			return postgres.Room{}, "", ErrSlugTaken
+		}
+		if attempt == slugAttempts {
+			return postgres.Room{}, "", err
+		}
+	}
+	if err != nil {
+		return postgres.Room{}, "", err
+	}
+
+	return room, ownerToken, nil
+}
+
+// UpdateAnonymity makes the room anonymous or lets authors sign their
+// questions again. Only the owner can do it.
+func (service *RoomService) UpdateAnonymity(ctx context.Context, actor Actor, roomID uuid.UUID, anonymous bool) (postgres.Room, error) {
+	var room postgres.Room
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		previous, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, previous); err != nil {
+			return err
+		}
+
+		room, err = query.UpdateRoomAnonymity(ctx, postgres.UpdateRoomAnonymityParams{
+			Anonymous:      anonymous,
+			ID:             roomID,
+			OrganizationID: previous.OrganizationID,
+		})
+		return err
+	})
+
+	return room, err
+}
+
+// DeleteRoom hides the room and its questions, or removes them for good when
+// purge is set. Only the owner can delete a room.
+func (service *RoomService) DeleteRoom(ctx context.Context, actor Actor, roomID uuid.UUID, purge bool) error {
+	return service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		room, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, room); err != nil {
+			return err
+		}
+
+		if purge {
+			_, err = query.DeleteRoom(ctx, postgres.DeleteRoomParams{ID: roomID, OrganizationID: room.OrganizationID})
+			return err
+		}
+
+		_, err = query.SoftDeleteRoom(ctx, postgres.SoftDeleteRoomParams{ID: roomID, OrganizationID: room.OrganizationID})
+		return err
+	})
+}
+
+// GetAuditLog lists every recorded change to the room and its questions,
+// oldest first. Only the owner can read it.
+func (service *RoomService) GetAuditLog(ctx context.Context, actor Actor, roomID uuid.UUID) ([]postgres.AuditLog, error) {
+	var entries []postgres.AuditLog
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		if _, err := getOwnedRoom(ctx, query, actor, roomID); err != nil {
+			return err
+		}
+
+		var err error
+		entries, err = query.GetRoomAuditLog(ctx, roomID)
+		return err
+	})
+
+	return entries, err
+}
+
+func (service *RoomService) GetRoom(ctx context.Context, roomID uuid.UUID) (postgres.Room, error) {
+	return getRoom(ctx, service.store, roomID)
+}
+
+// EnterRoom loads a room the actor is allowed into, by its ID or its slug.
+// The owner can always enter; a room that requires a join code also lets in
+// participants who were granted access or bring the code, which grants them
+// access for next time.
+func (service *RoomService) EnterRoom(ctx context.Context, actor Actor, reference string) (postgres.Room, error) {
+	var room postgres.Room
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		var err error
+		if room, err = findRoom(ctx, query, reference); err != nil {
+			return err
+		}
+		roomID := room.ID
+
+		if !requiresJoinCode(room.Visibility, room.OrganizationID) || actor.owns(room) {
+			return nil
+		}
+
+		participantHash := actor.participantHash()
+		if participantHash != nil {
+			granted, err := query.HasRoomGrant(ctx, postgres.HasRoomGrantParams{
+				RoomID:          roomID,
+				ParticipantHash: participantHash,
+			})
+			if err != nil || granted {
+				return err
+			}
+		}
+
+		if !auth.MatchJoinCode(actor.JoinCode, room.JoinCode.String) {
+			return ErrJoinCodeRequired
+		}
+
+		if participantHash == nil {
+			return nil
+		}
+
+		return query.CreateRoomGrant(ctx, postgres.CreateRoomGrantParams{
+			RoomID:          roomID,
+			ParticipantHash: participantHash,
+		})
+	})
+
+	return room, err
+}
+
+// requiresJoinCode tells whether entering a room takes its join code. Outside
+// the default organization every room does: naming an organization is no
+// credential, so its API keys and the owners of its rooms are the only ones
+// let in without the code.
+func requiresJoinCode(visibility string, organizationID uuid.UUID) bool {
+	return visibility == RoomVisibilityPrivate || organizationID != db.DefaultOrganizationID
+}
+
+// Export loads a room with all of its questions, most popular first.
+func (service *RoomService) Export(ctx context.Context, roomID uuid.UUID) (postgres.Room, []postgres.Question, error) {
+	var (
+		room      postgres.Room
+		questions []postgres.Question
+	)
+
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		var err error
+		if room, err = getRoom(ctx, query, roomID); err != nil {
+			return err
+		}
+
+		questions, err = query.GetRoomQuestionsByPopularity(ctx, roomID)
+		return err
+	})
+
+	return room, questions, err
+}
+
+// findRoom is getRoom for either the ID or the slug of the room.
+func findRoom(ctx context.Context, query postgres.Querier, reference string) (postgres.Room, error) {
+	if roomID, err := uuid.Parse(reference); err == nil {
+		return getRoom(ctx, query, roomID)
+	}
+
+	// No room can have a slug that doesn't validate.
+	slug := strings.ToLower(reference)
+	if ValidateSlug(slug) != nil {
+		return postgres.Room{}, ErrRoomNotFound
+	}
+
+	room, err := query.GetRoomBySlug(ctx, postgres.GetRoomBySlugParams{Slug: slug, OrganizationID: organizationID(ctx)})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return postgres.Room{}, ErrRoomNotFound
+	}
+
+	return room, err
+}
+
+func getRoom(ctx context.Context, query postgres.Querier, roomID uuid.UUID) (postgres.Room, error) {
+	room, err := query.GetRoom(ctx, postgres.GetRoomParams{ID: roomID, OrganizationID: organizationID(ctx)})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return postgres.Room{}, ErrRoomNotFound
+	}
+
+	return room, err
+}
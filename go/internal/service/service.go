@@ -0,0 +1,55 @@
+// Package service holds the application logic that sits between the HTTP
+// handlers and the sqlc generated queries. Operations that touch more than one
+// row run inside a single transaction and report failures as the domain errors
+// declared here, leaving the HTTP layer to decide on status codes.
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRoomNotFound     = errors.New("room not found")
+	ErrRoomClosed       = errors.New("room is closed")
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrPollNotFound     = errors.New("poll not found")
+	ErrPollClosed       = errors.New("poll is closed")
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrForbidden        = errors.New("forbidden")
+	ErrQuestionLocked   = errors.New("question can no longer be edited")
+	ErrJoinCodeRequired = errors.New("room requires a join code")
+	ErrSlugTaken        = errors.New("slug is already taken")
+
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrInvalidAPIKey        = errors.New("invalid API key")
+	ErrMissingScope         = errors.New("API key is missing a scope")
+)
+
+// maxTextLength mirrors the VARCHAR(255) columns of the room and question tables.
+const maxTextLength = 255
+
+func invalidInput(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidInput, fmt.Sprintf(format, args...))
+}
+
+// RowError reports why a single row of a bulk operation was rejected.
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"error"`
+}
+
+// ImportError lists every invalid row of an import. It matches
+// ErrInvalidInput with errors.Is.
+type ImportError struct {
+	Rows []RowError
+}
+
+func (err *ImportError) Error() string {
+	return fmt.Sprintf("%d invalid rows", len(err.Rows))
+}
+
+func (err *ImportError) Unwrap() error {
+	return ErrInvalidInput
+}
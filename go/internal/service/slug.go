@@ -0,0 +1,76 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	minSlugLength = 3
+	maxSlugLength = 64
+	// slugBaseLength leaves room for the suffix of a generated slug.
+	slugBaseLength = 40
+	// slugAttempts is how many generated slugs are tried before giving up.
+	slugAttempts = 5
+)
+
+// slugSuffixAlphabet leaves out the characters that are easily confused when
+// read out loud.
+const slugSuffixAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+var (
+	slugPattern    = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// ValidateSlug accepts lowercase words of letters and digits separated by
+// single dashes. UUIDs are rejected, since rooms and organizations can be
+// looked up by both.
+func ValidateSlug(slug string) error {
+	if len(slug) < minSlugLength || len(slug) > maxSlugLength {
+		return invalidInput("slug must have between %d and %d characters", minSlugLength, maxSlugLength)
+	}
+	if !slugPattern.MatchString(slug) {
+		return invalidInput("slug must only have lowercase letters and digits separated by dashes")
+	}
+	if _, err := uuid.Parse(slug); err == nil {
+		return invalidInput("slug must not be a UUID")
+	}
+
+	return nil
+}
+
+// NewSlug derives a slug from the room name, such as "go-ama-k7qm" for
+// "Go AMA!", with a random suffix so rooms can share a name.
+func NewSlug(name string) (string, error) {
+	base := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(base) > slugBaseLength {
+		base = strings.TrimRight(base[:slugBaseLength], "-")
+	}
+	if base == "" {
+		base = "room"
+	}
+
+	var suffix strings.Builder
+	max := big.NewInt(int64(len(slugSuffixAlphabet)))
+	for range 4 {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		suffix.WriteByte(slugSuffixAlphabet[n.Int64()])
+	}
+
+	return base + "-" + suffix.String(), nil
+}
+
+func isSlugTaken(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "room_slug_idx"
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pedrogiorgetti/ama/go/internal/db/postgres"
+)
+
+// SpotlightQuestion marks the question as the one the owner is answering now,
+// taking the spotlight from the previous one.
+func (service *RoomService) SpotlightQuestion(ctx context.Context, actor Actor, roomID, questionID uuid.UUID) (postgres.Room, postgres.Question, error) {
+	var (
+		room     postgres.Room
+		question postgres.Question
+	)
+
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		previous, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if previous.ClosedAt.Valid {
+			return ErrRoomClosed
+		}
+
+		if question, err = getRoomQuestion(ctx, query, roomID, questionID); err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, previous); err != nil {
+			return err
+		}
+
+		room, err = setSpotlight(ctx, query, roomID, pgtype.UUID{Bytes: questionID, Valid: true})
+		return err
+	})
+
+	return room, question, err
+}
+
+// ClearSpotlight leaves the room without a question in the spotlight.
+func (service *RoomService) ClearSpotlight(ctx context.Context, actor Actor, roomID uuid.UUID) (postgres.Room, error) {
+	var room postgres.Room
+	err := service.store.ExecTx(ctx, func(query postgres.Querier) error {
+		previous, err := getOwnedRoom(ctx, query, actor, roomID)
+		if err != nil {
+			return err
+		}
+
+		if err := setAuditActor(ctx, query, actor, previous); err != nil {
+			return err
+		}
+
+		room, err = setSpotlight(ctx, query, roomID, pgtype.UUID{})
+		return err
+	})
+
+	return room, err
+}
+
+// setSpotlight credits the question leaving the spotlight with the time it
+// spent there before handing the spotlight over.
+func setSpotlight(ctx context.Context, query postgres.Querier, roomID uuid.UUID, questionID pgtype.UUID) (postgres.Room, error) {
+	params := postgres.AddSpotlightTimeParams{ID: roomID, OrganizationID: organizationID(ctx)}
+	if err := query.AddSpotlightTime(ctx, params); err != nil {
+		return postgres.Room{}, err
+	}
+
+	return query.SetRoomSpotlight(ctx, postgres.SetRoomSpotlightParams{
+		QuestionID:     questionID,
+		ID:             roomID,
+		OrganizationID: params.OrganizationID,
+	})
+}
+
+// spotlights reports whether the question is in the room's spotlight.
+func spotlights(room postgres.Room, questionID uuid.UUID) bool {
+	return room.SpotlightQuestionID.Valid && room.SpotlightQuestionID.Bytes == questionID
+}
@@ -7,6 +7,9 @@ enum EWebsocketNotificationCategory {
   ReactionIncrease = 'question_reaction_increase',
   ReactionDecrease = 'question_reaction_decrease',
   Answered = 'question_answered',
+  Imported = 'questions_imported',
+  Deleted = 'question_deleted',
+  Edited = 'question_edited',
 }
 
 interface WebsocketNotificationData {
@@ -109,6 +112,55 @@ export function useQuestionsWebSocket({ roomId }: UseQuestionsWebSocket) {
             },
           );
           break;
+
+        case EWebsocketNotificationCategory.Edited:
+          queryClient.setQueryData<GetRoomQuestionsResponseData>(
+            ['questions', roomId],
+            currentData => {
+              if (!currentData) {
+                return undefined;
+              }
+
+              return {
+                list: currentData.list.map(question => {
+                  if (question.id === parsedData.value.id) {
+                    return {
+                      ...question,
+                      text: parsedData.value.text,
+                    };
+                  }
+
+                  return question;
+                }),
+                total: currentData.total,
+              };
+            },
+          );
+          break;
+
+        case EWebsocketNotificationCategory.Deleted:
+          queryClient.setQueryData<GetRoomQuestionsResponseData>(
+            ['questions', roomId],
+            currentData => {
+              if (!currentData) {
+                return undefined;
+              }
+
+              const list = currentData.list.filter(
+                question => question.id !== parsedData.value.id,
+              );
+
+              return {
+                list,
+                total: list.length,
+              };
+            },
+          );
+          break;
+
+        case EWebsocketNotificationCategory.Imported:
+          queryClient.invalidateQueries({ queryKey: ['questions', roomId] });
+          break;
       }
     };
 
@@ -12,6 +12,7 @@ interface CreateRoomRequest {
 interface CreateRoomResponse {
   id: string;
   name: string;
+  slug: string;
   created_at: string;
   updated_at: string;
 }
@@ -35,6 +36,7 @@ export async function createRoomRequest({
   return {
     id: data.id,
     name: data.name,
+    slug: data.slug,
     createdAt: data.created_at,
     updatedAt: data.updated_at,
   };
@@ -1,4 +1,5 @@
 import { Question } from '../../interfaces/question';
+import { getParticipantId } from '../../lib/participant';
 
 interface CreateQuestionRequest {
   params: {
@@ -33,6 +34,7 @@ export async function createQuestionRequest({
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
+        'X-Participant-ID': getParticipantId(),
       },
       body: JSON.stringify(body),
     },
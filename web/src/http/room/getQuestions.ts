@@ -8,11 +8,13 @@ interface GetRoomQuestionsRequest {
 
 interface GetRoomQuestionsResponse {
   list: {
-    ID: string;
-    Text: string;
-    RoomID: string;
-    ReactionCount: number;
-    Answered: boolean;
+    id: string;
+    text: string;
+    room_id: string;
+    reaction_count: number;
+    answered: boolean;
+    created_at: string;
+    updated_at: string;
   }[];
   total: number;
 }
@@ -38,11 +40,13 @@ export async function getRoomQuestionsRequest({
   const data: GetRoomQuestionsResponse = await response.json();
 
   const questionsFormatted: Question[] = data.list.map(item => ({
-    id: item.ID,
-    text: item.Text,
-    roomId: item.RoomID,
-    reactionCount: item.ReactionCount,
-    isAnswered: item.Answered,
+    id: item.id,
+    text: item.text,
+    roomId: item.room_id,
+    reactionCount: item.reaction_count,
+    isAnswered: item.answered,
+    createdAt: item.created_at,
+    updatedAt: item.updated_at,
   }));
 
   return {
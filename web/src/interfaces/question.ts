@@ -4,4 +4,6 @@ export interface Question {
   reactionCount: number;
   roomId: string;
   isAnswered: boolean;
+  createdAt?: string;
+  updatedAt?: string;
 }
@@ -1,6 +1,7 @@
 export interface Room {
   id: string;
   name: string;
+  slug: string;
   createdAt: string;
   updatedAt: string;
 }
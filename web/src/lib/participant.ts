@@ -0,0 +1,12 @@
+const PARTICIPANT_ID_KEY = 'participant-id';
+
+export function getParticipantId(): string {
+  let participantId = localStorage.getItem(PARTICIPANT_ID_KEY);
+
+  if (!participantId) {
+    participantId = crypto.randomUUID();
+    localStorage.setItem(PARTICIPANT_ID_KEY, participantId);
+  }
+
+  return participantId;
+}
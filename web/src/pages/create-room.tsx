@@ -36,7 +36,7 @@ export function CreateRoom() {
         },
       });
 
-      navigate(pages.room.details(room.id));
+      navigate(pages.room.details(room.slug));
     } catch {
       setIsLoading(false);
       toast.error(`